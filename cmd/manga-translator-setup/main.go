@@ -28,7 +28,7 @@ func main() {
 	config.Setup(settings, &cfg)
 
 	// We only want to start from scratch if there is no existing config, otherwise we modify existing config.
-	modify := cfg != config.File{}
+	modify := !cfg.IsBlank()
 
 	config.Create(modify)
 }
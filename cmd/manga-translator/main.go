@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -10,6 +12,7 @@ import (
 
 	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
 	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/session"
 	"github.com/Drack112/Anime-OCR-Translator/pkg/window"
 	log "github.com/sirupsen/logrus"
 )
@@ -31,23 +34,62 @@ func main() {
 	}
 	defer f.Close()
 
+	// Subcommands run without a window.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "watch":
+			var cfg config.File
+			config.Setup(settings, &cfg)
+			watch(cfg, os.Args[2:])
+			return
+		case "serve":
+			var cfg config.File
+			config.Setup(settings, &cfg)
+			serve(cfg, os.Args[2:])
+			return
+		}
+	}
+
 	// Parse flags.
 	urlImagePtr := flag.Bool("url", false, "Use an image from a URL instead of a local file.")
 	clipImagePtr := flag.Bool("clip", false, "Use an image from the clipboard.") // overrides url
+	resumePtr := flag.Bool("resume", false, "Reopen the pages of the last session.")
+	capturePtr := flag.Bool("capture", false, "Select a region of the screen to translate with a screenshot helper.")
+	watchClipPtr := flag.Bool("watch-clip", false, "Add every image copied to the clipboard as a new page and translate it.")
 	flag.Parse()
 	log.Infof("Use URL image: %v", *urlImagePtr)
 	log.Infof("Use clipboard image: %v", *clipImagePtr)
+	log.Infof("Resume last session: %v", *resumePtr)
+	log.Infof("Capture screen region: %v", *capturePtr)
+	log.Infof("Watch clipboard: %v", *watchClipPtr)
 
 	// Set up config, create new config if necessary.
 	var cfg config.File
 	config.Setup(settings, &cfg)
 
+	var resume *session.File
+	if *resumePtr {
+		if len(flag.Args()) > 0 {
+			log.Fatal("Paths or URLs can't be given together with -resume.")
+		}
+		s, err := session.Load()
+		if err != nil {
+			log.Fatalf("No session to resume: %v", err)
+		}
+		resume = &s
+	}
+
 	// Open/download selected image and get its info.
-	if len(flag.Args()) == 0 && !*clipImagePtr {
+	if len(flag.Args()) == 0 && !*clipImagePtr && resume == nil && !*watchClipPtr && !*capturePtr {
 		log.Fatal("No path or URL given.")
 	}
 	var imgPath []string
-	if !*clipImagePtr {
+	if resume != nil {
+		for _, page := range resume.Pages {
+			imgPath = append(imgPath, page.Source)
+		}
+		log.Infof("Resumed Image(s): %v", imgPath)
+	} else if !*clipImagePtr {
 		imgPath = flag.Args()
 		log.Infof("All Selected Image(s): %v", imgPath)
 	} else {
@@ -55,33 +97,82 @@ func main() {
 		imgPath = append(imgPath, "clipboard")
 	}
 
-	if len(imgPath) == 0 {
+	if len(imgPath) == 0 && !*watchClipPtr && !*capturePtr {
 		log.Fatal("No images provided.")
 	}
 
+	// Start watching before opening the other images, so that nothing copied in the meantime is missed.
+	var clip <-chan imageW.TranslatorImage
+	if *watchClipPtr {
+		clip, err = imageW.WatchClipboard(context.Background())
+		if err != nil {
+			log.Fatalf("Unable to watch the clipboard: %v", err)
+		}
+	}
+
 	var img []imageW.TranslatorImage
 
-	for _, paths := range imgPath {
-		log.Debugf("Getting image info for: %v", imgPath)
-		newImage := imageW.Open(paths, *urlImagePtr, *clipImagePtr)
+	if resume != nil {
+		// Pages which were moved or deleted since the last session are skipped.
+		page := resume.Page
+		for i, paths := range imgPath {
+			newImage, err := imageW.Load(paths, resume.Pages[i].URL, false)
+			if err != nil {
+				log.Warningf("Skipping page of the last session: %v", err)
+				fmt.Fprintf(os.Stderr, "Skipping %v: %v\n", paths, err)
+				if i < resume.Page {
+					page--
+				}
+				continue
+			}
+			img = append(img, newImage)
+		}
+		if len(img) == 0 && !*watchClipPtr && !*capturePtr {
+			log.Fatal("None of the pages of the last session could be opened.")
+		}
+		resume.Page = page
+	} else {
+		for _, paths := range imgPath {
+			log.Debugf("Getting image info for: %v", imgPath)
+			img = append(img, imageW.Open(paths, *urlImagePtr, *clipImagePtr))
+		}
+	}
+
+	if *capturePtr {
+		newImage, err := imageW.Capture(cfg.Capture.Command)
+		if err != nil {
+			log.Fatal(err)
+		}
 		img = append(img, newImage)
 	}
 
+	// The window is opened with the first copied image if there are no other images.
+	if len(img) == 0 {
+		fmt.Println("Waiting for an image to be copied to the clipboard...")
+		log.Info("Waiting for an image to be copied to the clipboard")
+		img = append(img, <-clip)
+	}
+
 	// We need this ratio to scale the image down/up to the required starting size.
 	ratio := imageW.GetRatio(img[0].Dimensions, maxDim)
 	firstWidth := float32(img[0].Dimensions.Width)
 	firstHeight := float32(img[0].Dimensions.Height)
 
+	windowSize := app.Size(unit.Dp(ratio*firstWidth), unit.Dp(ratio*firstHeight))
+	if resume != nil && resume.Width > 0 && resume.Height > 0 {
+		windowSize = app.Size(unit.Dp(resume.Width), unit.Dp(resume.Height))
+	}
+
 	go func() {
 		// Create new window.
 		w := app.NewWindow(
 			app.Title("Manga Translator"),
 
-			app.Size(unit.Dp(ratio*firstWidth), unit.Dp(ratio*firstHeight)),
+			windowSize,
 			app.MinSize(unit.Dp(600), unit.Dp(300)),
 		)
 
-		if err := window.DrawFrame(w, img, cfg); err != nil {
+		if err := window.DrawFrame(w, img, cfg, resume, clip); err != nil {
 			log.Fatal(err)
 		}
 		os.Exit(0)
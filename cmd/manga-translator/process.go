@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+)
+
+// jsonPage is the result of a page as written by the headless commands.
+type jsonPage struct {
+	Hash   string      `json:"hash"`
+	Source string      `json:"source,omitempty"`
+	Blocks []jsonBlock `json:"blocks"`
+}
+
+type jsonBlock struct {
+	Text       string       `json:"text"`
+	Translated string       `json:"translated"` // Human translation if there is one.
+	Hidden     bool         `json:"hidden,omitempty"`
+	Vertices   []jsonVertex `json:"vertices"` // In pixels of the original image.
+}
+
+type jsonVertex struct {
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+}
+
+func newJSONPage(img imageW.TranslatorImage, blocks []detect.TextBlock) jsonPage {
+	page := jsonPage{
+		Hash:   img.Hash,
+		Source: img.Source,
+		Blocks: []jsonBlock{},
+	}
+	for _, block := range blocks {
+		b := jsonBlock{
+			Text:       block.Text,
+			Translated: block.Final(),
+			Hidden:     block.Hidden,
+		}
+		for _, v := range block.Vertices {
+			b.Vertices = append(b.Vertices, jsonVertex{X: v.X, Y: v.Y})
+		}
+		page.Blocks = append(page.Blocks, b)
+	}
+	return page
+}
+
+// export writes the page to a JSON file in the given directory, named after the image file.
+func export(dir string, page jsonPage) error {
+	name := strings.TrimSuffix(filepath.Base(page.Source), filepath.Ext(page.Source))
+	if name == "" || name == "." {
+		name = page.Hash
+	}
+
+	d, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name+".json"), d, 0644)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/cache"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/pipeline"
+)
+
+// maxUploadSize limits the size of an uploaded image.
+const maxUploadSize = 32 << 20
+
+// serve runs a local HTTP server which detects and translates the text of uploaded images, until the program is interrupted.
+//
+//	POST /pages         the image as the request body, or as the "image" field of a multipart form
+//	GET  /pages/{hash}  the cached result of a page translated before
+//
+// Both return the page as JSON, like the export of the watch command.
+func serve(cfg config.File, args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addrPtr := flags.String("addr", "127.0.0.1:8421", "Address to listen on. Keep it on localhost, every request may use your API quota.")
+	corsPtr := flags.String("cors", "", `Origin allowed to call the API from a web page, e.g. "https://example.com" or "*".`)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: manga-translator serve [-addr host:port] [-cors origin]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	clients := pipeline.NewClients(&cfg)
+	defer clients.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /pages", func(w http.ResponseWriter, r *http.Request) {
+		postPage(&cfg, clients, w, r)
+	})
+	mux.HandleFunc("GET /pages/{hash}", func(w http.ResponseWriter, r *http.Request) {
+		getPage(&cfg, w, r)
+	})
+
+	// An interrupt cancels the requests in progress, and the program exits once they have returned.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:        *addrPtr,
+		Handler:     allowOrigin(*corsPtr, mux),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	log.Infof("Serving API on: %v", *addrPtr)
+	fmt.Printf("Serving on http://%v\n", *addrPtr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatalf("Unable to serve API: %v", err)
+	}
+	<-stopped
+	log.Info("Stopped serving API")
+}
+
+// allowOrigin adds the CORS headers for the origin, so that scripts running in a browser can call the API.
+func allowOrigin(origin string, h http.Handler) http.Handler {
+	if origin == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func postPage(cfg *config.File, clients *pipeline.Clients, w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			writeError(w, uploadStatus(err), fmt.Errorf("no image in form: %v", err))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	img, err := imageW.Decode(body)
+	if err != nil {
+		writeError(w, uploadStatus(err), fmt.Errorf("invalid image: %v", err))
+		return
+	}
+
+	log.Infof("Translating uploaded page: %v", img.Hash)
+	res, err := pipeline.Process(r.Context(), img, pipeline.Options{Config: cfg, Clients: clients})
+	switch {
+	case errors.Is(err, pipeline.ErrBlankConfig):
+		writeError(w, http.StatusServiceUnavailable, err)
+	case errors.As(err, new(*cache.Error)):
+		log.Errorf("Unable to translate %v: %v", img.Hash, err)
+		writeError(w, http.StatusInternalServerError, err)
+	case r.Context().Err() != nil:
+		// The client has gone away or the server is shutting down, which is not a failure of the APIs.
+		log.Infof("Translation of %v was cancelled", img.Hash)
+		writeError(w, http.StatusServiceUnavailable, r.Context().Err())
+	case err != nil:
+		log.Errorf("Unable to translate %v: %v", img.Hash, err)
+		writeError(w, http.StatusBadGateway, err)
+	default:
+		writeJSON(w, http.StatusOK, newJSONPage(img, res.Blocks))
+	}
+}
+
+// uploadStatus returns the status of a request whose image could not be read.
+func uploadStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+func getPage(cfg *config.File, w http.ResponseWriter, r *http.Request) {
+	hash := r.PathValue("hash")
+	blocks, translateOnly, err := cache.Check(hash, cfg.Translation.SelectedService)
+	if err != nil {
+		log.Errorf("Unable to look up %v: %v", hash, err)
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if blocks == nil || translateOnly {
+		writeError(w, http.StatusNotFound, fmt.Errorf("page %v is not in the cache", hash))
+		return
+	}
+	writeJSON(w, http.StatusOK, newJSONPage(imageW.TranslatorImage{Hash: hash}, blocks))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warningf("Unable to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
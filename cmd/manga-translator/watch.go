@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/pipeline"
+)
+
+// settleTime is how long a new file must stay unchanged before it is opened, so that it is not read while it is still being written.
+var settleTime = 2 * time.Second
+
+// watch translates the images in a directory and every image added to it afterwards, until the program is interrupted.
+// The results are stored in the cache, so that the pages open right away in the viewer.
+func watch(cfg config.File, args []string) {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	exportPtr := flags.String("export", "", "Also write the text of every page as JSON to this directory.")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: manga-translator watch [-export dir] <dir>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	dir := flags.Arg(0)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Fatalf("Unable to watch directory: %v", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(dir); err != nil {
+		log.Fatalf("Unable to watch %v: %v", dir, err)
+	}
+	log.Infof("Watching directory: %v", dir)
+	fmt.Printf("Watching %v for new images...\n", dir)
+
+	// An interrupt cancels the page being translated, and the program exits once it has stopped.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	clients := pipeline.NewClients(&cfg)
+	defer clients.Close()
+
+	// Pages are translated in the order they were added. Pages which are waiting are translated together,
+	// so that the images already in the directory are detected with as few requests as possible.
+	queue := make(chan string, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case path := <-queue:
+				paths := []string{path}
+			batch:
+				for len(paths) < detect.MaxBatchSize {
+					select {
+					case path := <-queue:
+						paths = append(paths, path)
+					default:
+						break batch
+					}
+				}
+				translateFiles(ctx, &cfg, clients, paths, *exportPtr)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Images which are already in the directory are translated first. Pages in the cache are skipped quickly.
+	if paths, err := imageW.Paths(dir); err == nil {
+		for _, path := range paths {
+			select {
+			case queue <- path:
+			case <-ctx.Done():
+			}
+		}
+	}
+
+	// Every event restarts the timer of the file, so it is only queued once it has been written completely.
+	var mu sync.Mutex
+	pending := make(map[string]*time.Timer)
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			log.Info("Stopped watching directory")
+			return
+
+		case e, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if e.Op&(fsnotify.Create|fsnotify.Write) == 0 || !imageW.IsImage(e.Name) {
+				continue
+			}
+
+			mu.Lock()
+			if t, ok := pending[e.Name]; ok {
+				t.Reset(settleTime)
+			} else {
+				path := e.Name
+				pending[path] = time.AfterFunc(settleTime, func() {
+					mu.Lock()
+					delete(pending, path)
+					mu.Unlock()
+					select {
+					case queue <- path:
+					case <-ctx.Done():
+					}
+				})
+			}
+			mu.Unlock()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Errorf("Directory watch error: %v", err)
+		}
+	}
+}
+
+// translateFiles translates the pages of the given files, detecting their text together.
+func translateFiles(ctx context.Context, cfg *config.File, clients *pipeline.Clients, paths []string, exportDir string) {
+	var images []imageW.TranslatorImage
+	var loaded []string // Path of every image.
+	for _, path := range paths {
+		log.Infof("Translating new page: %v", path)
+		img, err := imageW.Load(path, false, false)
+		if err != nil {
+			log.Errorf("Unable to open %v: %v", path, err)
+			fmt.Printf("%v: %v\n", path, err)
+			continue
+		}
+		images = append(images, img)
+		loaded = append(loaded, path)
+	}
+
+	results := pipeline.ProcessBatch(ctx, images, pipeline.Options{Config: cfg, Clients: clients})
+	if ctx.Err() != nil {
+		return
+	}
+
+	for i, img := range images {
+		path, res := loaded[i], results[img.Hash]
+		if res.Err != nil {
+			log.Errorf("Unable to translate %v: %v", path, res.Err)
+			fmt.Printf("%v: %v\n", path, res.Err)
+			continue
+		}
+		fmt.Printf("%v: %d text blocks\n", path, len(res.Blocks))
+
+		if exportDir == "" {
+			continue
+		}
+		if err := export(exportDir, newJSONPage(img, res.Blocks)); err != nil {
+			log.Errorf("Export failed: %v", err)
+		}
+	}
+}
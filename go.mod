@@ -6,6 +6,7 @@ require (
 	cloud.google.com/go/translate v1.12.4
 	cloud.google.com/go/vision v1.2.0
 	gioui.org v0.0.0-20220307121938-3e18a310af31
+	github.com/fsnotify/fsnotify v1.5.1
 	github.com/gonoto/notosans v0.0.0-20200703162533-d78fef05ce80
 	github.com/inancgumus/screen v0.0.0-20190314163918-06e984b86ed3
 	github.com/labstack/gommon v0.4.2
@@ -14,8 +15,10 @@ require (
 	golang.design/x/clipboard v0.6.2
 	golang.org/x/image v0.0.0-20211028202545-6944b10bf410
 	golang.org/x/text v0.23.0
+	golang.org/x/time v0.10.0
 	google.golang.org/api v0.224.0
 	google.golang.org/genproto v0.0.0-20250303144028-a0af3efb3deb
+	google.golang.org/grpc v1.71.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
@@ -29,7 +32,6 @@ require (
 	gioui.org/cpu v0.0.0-20210817075930-8d6a761490d2 // indirect
 	gioui.org/shader v1.0.6 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
-	github.com/fsnotify/fsnotify v1.5.1 // indirect
 	github.com/go-logr/logr v1.4.2 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/s2a-go v0.1.9 // indirect
@@ -64,10 +66,8 @@ require (
 	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
 	golang.org/x/term v0.30.0 // indirect
-	golang.org/x/time v0.10.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250303144028-a0af3efb3deb // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250303144028-a0af3efb3deb // indirect
-	google.golang.org/grpc v1.71.0 // indirect
 	google.golang.org/protobuf v1.36.5 // indirect
 	gopkg.in/ini.v1 v1.66.4 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -3,6 +3,7 @@ package cache
 import (
 	"encoding/gob"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -21,46 +22,57 @@ type data struct {
 
 var mu sync.Mutex
 
-func read() []data {
+// Error is returned if the cache file cannot be read or written.
+type Error struct {
+	Op  string // "read" or "write".
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unable to %v cache: %v", e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func read() ([]data, error) {
 	var cacheData []data
 
 	cachePath := filepath.Join(config.Path(), "mtl-cache.bin")
 	cacheFile, err := os.Open(cachePath)
 	if errors.Is(err, os.ErrNotExist) {
-		cacheFile, err = os.Create(cachePath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer cacheFile.Close()
-
-		enc := gob.NewEncoder(cacheFile)
-		if err := enc.Encode(cacheData); err != nil {
-			log.Fatalf("Cache creation failed: %v", err)
+		if err := write(cacheData); err != nil {
+			return nil, err
 		}
-
-		return cacheData
+		return cacheData, nil
 	} else if err != nil {
-		log.Fatal(err)
+		return nil, &Error{"read", err}
 	}
 	defer cacheFile.Close()
 
 	dec := gob.NewDecoder(cacheFile)
 	if err := dec.Decode(&cacheData); err != nil {
-		log.Fatal(err)
+		return nil, &Error{"read", err}
 	}
-	return cacheData
+	return cacheData, nil
 }
 
-func Check(h string, service string) (blocks []detect.TextBlock, translateOnly bool) {
+// Check returns the cached blocks of the given image. If they were only cached with another service,
+// translateOnly is true and the blocks still need to be translated.
+func Check(h string, service string) (blocks []detect.TextBlock, translateOnly bool, err error) {
 	mu.Lock()
 	defer mu.Unlock()
 
-	cacheData := read()
+	cacheData, err := read()
+	if err != nil {
+		return nil, false, err
+	}
 	var existingBlocks []detect.TextBlock
 	for _, data := range cacheData {
 		if h == data.Hash && data.Service == service {
 			log.Info("Image found in cache, skipping API requests.")
-			return data.Blocks, false
+			return data.Blocks, false, nil
 		} else if h == data.Hash {
 			existingBlocks = data.Blocks
 		}
@@ -68,26 +80,23 @@ func Check(h string, service string) (blocks []detect.TextBlock, translateOnly b
 
 	if existingBlocks != nil {
 		log.Info("Image text found in cache, performing API requests")
-		return existingBlocks, true
+		return existingBlocks, true, nil
 	}
 
 	log.Info("Image not found in cache, performing API requests")
-	return nil, false
+	return nil, false, nil
 }
 
-func Add(h string, service string, blocks []detect.TextBlock) {
+// Add adds the blocks of the given image and service to the cache.
+func Add(h string, service string, blocks []detect.TextBlock) error {
 	mu.Lock()
 	defer mu.Unlock()
 
 	log.Debugf("Adding new image to cache. sha256:%v", h)
-	cacheData := read()
-
-	cachePath := filepath.Join(config.Path(), "mtl-cache.bin")
-	cacheFile, err := os.OpenFile(cachePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	cacheData, err := read()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer cacheFile.Close()
 
 	newData := data{
 		Hash:    h,
@@ -95,8 +104,52 @@ func Add(h string, service string, blocks []detect.TextBlock) {
 		Blocks:  blocks,
 	}
 	cacheData = append(cacheData, newData)
+	return write(cacheData)
+}
+
+// Update replaces the cached blocks of the given image and service.
+// A new entry is added if the image has not been cached with that service yet.
+func Update(h string, service string, blocks []detect.TextBlock) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	log.Debugf("Updating image in cache. sha256:%v", h)
+	cacheData, err := read()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range cacheData {
+		if cacheData[i].Hash == h && cacheData[i].Service == service {
+			cacheData[i].Blocks = blocks
+			found = true
+		}
+	}
+	if !found {
+		cacheData = append(cacheData, data{
+			Hash:    h,
+			Service: service,
+			Blocks:  blocks,
+		})
+	}
+	return write(cacheData)
+}
+
+func write(cacheData []data) error {
+	cachePath := filepath.Join(config.Path(), "mtl-cache.bin")
+	cacheFile, err := os.OpenFile(cachePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return &Error{"write", err}
+	}
+
 	enc := gob.NewEncoder(cacheFile)
 	if err := enc.Encode(cacheData); err != nil {
-		log.Fatalf("Cache write failed: %v", err)
+		cacheFile.Close()
+		return &Error{"write", err}
+	}
+	if err := cacheFile.Close(); err != nil {
+		return &Error{"write", err}
 	}
+	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
 	"cloud.google.com/go/translate"
@@ -37,6 +38,97 @@ type File struct {
 			APIKey string `yaml:"apiKey,omitempty"`
 		} `yaml:"deepL,omitempty"`
 	} `yaml:"translation"`
+	Filter struct {
+		Patterns  []string `yaml:"patterns,omitempty"`
+		MinWidth  int      `yaml:"minWidth,omitempty"`
+		MinHeight int      `yaml:"minHeight,omitempty"`
+	} `yaml:"filter,omitempty"`
+	Capture struct {
+		Command string `yaml:"command,omitempty"`
+	} `yaml:"capture,omitempty"`
+	Font   Font `yaml:"font,omitempty"`
+	Limits struct {
+		Concurrency          int `yaml:"concurrency,omitempty"`
+		VisionPerMinute      int `yaml:"visionPerMinute,omitempty"`
+		TranslationPerMinute int `yaml:"translationPerMinute,omitempty"`
+		Retries              int `yaml:"retries,omitempty"`
+	} `yaml:"limits,omitempty"`
+	Keys  Keys  `yaml:"keys,omitempty"`
+	Theme Theme `yaml:"theme,omitempty"`
+}
+
+// Font is the font of the text panels of the viewer. The settings of the original and translated text
+// replace the shared settings, so that each panel can use its own font.
+type Font struct {
+	FontSettings `yaml:",inline" mapstructure:",squash"`
+	Original     FontSettings `yaml:"original,omitempty"`
+	Translated   FontSettings `yaml:"translated,omitempty"`
+}
+
+// FontSettings are the font file, text size and line spacing of a panel. Unset values are zero.
+type FontSettings struct {
+	Path        string  `yaml:"path,omitempty"`
+	Size        float32 `yaml:"size,omitempty"`
+	LineSpacing float32 `yaml:"lineSpacing,omitempty"`
+}
+
+// OriginalSettings returns the settings of the original text, using the shared settings for those it does not set.
+func (f Font) OriginalSettings() FontSettings {
+	return f.Original.or(f.FontSettings)
+}
+
+// TranslatedSettings returns the settings of the translated text, using the shared settings for those it does not set.
+func (f Font) TranslatedSettings() FontSettings {
+	return f.Translated.or(f.FontSettings)
+}
+
+func (s FontSettings) or(shared FontSettings) FontSettings {
+	if s.Path == "" {
+		s.Path = shared.Path
+	}
+	if s.Size == 0 {
+		s.Size = shared.Size
+	}
+	if s.LineSpacing == 0 {
+		s.LineSpacing = shared.LineSpacing
+	}
+	return s
+}
+
+// Keys are the keyboard shortcuts of the viewer. Every action can have several bindings,
+// written as a key name with optional modifiers, such as "D", "Right" or "Ctrl+Shift+C".
+// Actions without any bindings use the default bindings.
+type Keys struct {
+	NextPage         []string `yaml:"nextPage,omitempty"`
+	PreviousPage     []string `yaml:"previousPage,omitempty"`
+	FirstPage        []string `yaml:"firstPage,omitempty"`
+	LastPage         []string `yaml:"lastPage,omitempty"`
+	NextBlock        []string `yaml:"nextBlock,omitempty"`
+	PreviousBlock    []string `yaml:"previousBlock,omitempty"`
+	CopyOriginal     []string `yaml:"copyOriginal,omitempty"`
+	CopyTranslated   []string `yaml:"copyTranslated,omitempty"`
+	ToggleOverlay    []string `yaml:"toggleOverlay,omitempty"`
+	ShowHidden       []string `yaml:"showHidden,omitempty"`
+	SelectRegion     []string `yaml:"selectRegion,omitempty"`
+	ZoomIn           []string `yaml:"zoomIn,omitempty"`
+	ZoomOut          []string `yaml:"zoomOut,omitempty"`
+	ZoomReset        []string `yaml:"zoomReset,omitempty"`
+	Retranslate      []string `yaml:"retranslate,omitempty"`
+	IncreaseTextSize []string `yaml:"increaseTextSize,omitempty"`
+	DecreaseTextSize []string `yaml:"decreaseTextSize,omitempty"`
+	ToggleThumbnails []string `yaml:"toggleThumbnails,omitempty"`
+	CycleView        []string `yaml:"cycleView,omitempty"`
+	GoToPage         []string `yaml:"goToPage,omitempty"`
+	Open             []string `yaml:"open,omitempty"`
+	Paste            []string `yaml:"paste,omitempty"`
+	Capture          []string `yaml:"capture,omitempty"`
+	Help             []string `yaml:"help,omitempty"`
+	Quit             []string `yaml:"quit,omitempty"`
+}
+
+// IsBlank returns true if the config has not been set up.
+func (f File) IsBlank() bool {
+	return reflect.DeepEqual(f, File{})
 }
 
 // deepLLanguage is the structure of language objects returned from the language list API.
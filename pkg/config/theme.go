@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Theme is the color theme of the viewer. Colors are written as "#RRGGBB" or "#RRGGBBAA" and
+// replace the colors of the preset, so that a preset can be adjusted without repeating all of it.
+type Theme struct {
+	Preset       string   `yaml:"preset,omitempty"` // "dark", "light" or "high-contrast", dark if empty.
+	Background   string   `yaml:"background,omitempty"`
+	Text         string   `yaml:"text,omitempty"`
+	Divider      string   `yaml:"divider,omitempty"`
+	BlockColors  []string `yaml:"blockColors,omitempty"`
+	BlockOpacity float32  `yaml:"blockOpacity,omitempty"` // Opacity of the block fills, from 0 to 1.
+}
+
+// Palette is a theme with all of its colors resolved.
+type Palette struct {
+	Background        color.NRGBA
+	Text              color.NRGBA
+	Divider           color.NRGBA // Also used for buttons and secondary text.
+	OverlayBackground color.NRGBA
+	OverlayText       color.NRGBA
+	BlockColors       []color.NRGBA
+	BlockOpacity      float32
+}
+
+// presets are the built-in themes.
+var presets = map[string]Palette{
+	"dark": {
+		Background:        color.NRGBA{R: 0x2B, G: 0x2B, B: 0x2B, A: 0xFF},
+		Text:              color.NRGBA{R: 0xCF, G: 0xCF, B: 0xCF, A: 0xFF},
+		Divider:           color.NRGBA{R: 0x75, G: 0x75, B: 0x75, A: 0xFF},
+		OverlayBackground: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		OverlayText:       color.NRGBA{A: 0xFF},
+		BlockColors: []color.NRGBA{
+			{R: 255, A: 255},         // Red
+			{G: 255, A: 255},         // Green
+			{B: 255, A: 255},         // Blue
+			{R: 255, G: 255, A: 255}, // Yellow
+			{R: 255, B: 255, A: 255}, // Violet
+			{G: 255, B: 255, A: 255}, // Cyan
+		},
+		BlockOpacity: 0.25,
+	},
+	"light": {
+		Background:        color.NRGBA{R: 0xF5, G: 0xF5, B: 0xF5, A: 0xFF},
+		Text:              color.NRGBA{R: 0x21, G: 0x21, B: 0x21, A: 0xFF},
+		Divider:           color.NRGBA{R: 0xBD, G: 0xBD, B: 0xBD, A: 0xFF},
+		OverlayBackground: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		OverlayText:       color.NRGBA{A: 0xFF},
+		BlockColors: []color.NRGBA{
+			{R: 0xE5, G: 0x39, B: 0x35, A: 0xFF}, // Red
+			{R: 0x43, G: 0xA0, B: 0x47, A: 0xFF}, // Green
+			{R: 0x1E, G: 0x88, B: 0xE5, A: 0xFF}, // Blue
+			{R: 0xF9, G: 0xA8, B: 0x25, A: 0xFF}, // Yellow
+			{R: 0x8E, G: 0x24, B: 0xAA, A: 0xFF}, // Violet
+			{R: 0x00, G: 0xAC, B: 0xC1, A: 0xFF}, // Cyan
+		},
+		BlockOpacity: 0.2,
+	},
+	"high-contrast": {
+		Background:        color.NRGBA{A: 0xFF},
+		Text:              color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		Divider:           color.NRGBA{R: 0x59, G: 0x59, B: 0x59, A: 0xFF},
+		OverlayBackground: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		OverlayText:       color.NRGBA{A: 0xFF},
+		BlockColors: []color.NRGBA{
+			{R: 255, G: 255, A: 255}, // Yellow
+			{G: 255, B: 255, A: 255}, // Cyan
+			{R: 255, B: 255, A: 255}, // Magenta
+		},
+		BlockOpacity: 0.35,
+	},
+}
+
+// Palette returns the colors of the preset with the colors set in the theme applied on top.
+// Unknown presets and invalid colors are logged and ignored.
+func (t Theme) Palette() Palette {
+	preset := t.Preset
+	if preset == "" {
+		preset = "dark"
+	}
+	p, ok := presets[preset]
+	if !ok {
+		log.Warningf("Unknown theme preset %q, using the dark theme", t.Preset)
+		p = presets["dark"]
+	}
+
+	setColor(&p.Background, t.Background)
+	setColor(&p.Text, t.Text)
+	setColor(&p.Divider, t.Divider)
+
+	if len(t.BlockColors) > 0 {
+		var colors []color.NRGBA
+		for _, s := range t.BlockColors {
+			c, err := ParseColor(s)
+			if err != nil {
+				log.Warningf("Invalid block color: %v", err)
+				continue
+			}
+			colors = append(colors, c)
+		}
+		if len(colors) > 0 {
+			p.BlockColors = colors
+		}
+	}
+
+	if t.BlockOpacity > 0 && t.BlockOpacity <= 1 {
+		p.BlockOpacity = t.BlockOpacity
+	} else if t.BlockOpacity != 0 {
+		log.Warningf("Block opacity %v is not between 0 and 1", t.BlockOpacity)
+	}
+	return p
+}
+
+func setColor(c *color.NRGBA, s string) {
+	if s == "" {
+		return
+	}
+	parsed, err := ParseColor(s)
+	if err != nil {
+		log.Warningf("Invalid theme color: %v", err)
+		return
+	}
+	*c = parsed
+}
+
+// ParseColor parses a color written as "#RRGGBB" or "#RRGGBBAA".
+func ParseColor(s string) (color.NRGBA, error) {
+	c := color.NRGBA{A: 0xFF}
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+
+	var err error
+	switch len(hex) {
+	case 6:
+		_, err = fmt.Sscanf(hex, "%02x%02x%02x", &c.R, &c.G, &c.B)
+	case 8:
+		_, err = fmt.Sscanf(hex, "%02x%02x%02x%02x", &c.R, &c.G, &c.B, &c.A)
+	default:
+		return c, fmt.Errorf("%q is not written as #RRGGBB or #RRGGBBAA", s)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%q: %v", s, err)
+	}
+	return c, nil
+}
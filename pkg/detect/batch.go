@@ -0,0 +1,143 @@
+package detect
+
+import (
+	"context"
+	"errors"
+	"image"
+	"sync"
+
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/throttle"
+	log "github.com/sirupsen/logrus"
+	pb "google.golang.org/genproto/googleapis/cloud/vision/v1"
+	"google.golang.org/grpc/status"
+)
+
+// MaxBatchSize is the most images the Vision API accepts in one request.
+const MaxBatchSize = 16
+
+// maxBatchBytes keeps a request below the size limit of the Vision API, as manga pages can be large.
+const maxBatchBytes = 8 << 20
+
+// DetectPages detects the text of several pages like DetectTiles, given as the tiles of every page.
+// The tiles of all pages are sent in as few requests as possible. It returns the blocks and the error of every page.
+func (c *Client) DetectPages(ctx context.Context, pages [][]imageW.Tile) ([][]TextBlock, []error) {
+	var images []*image.RGBA
+	for _, tiles := range pages {
+		for _, tile := range tiles {
+			images = append(images, tile.Image)
+		}
+	}
+	annotations, annotationErrs := c.BatchAnnotate(ctx, images)
+
+	blocks := make([][]TextBlock, len(pages))
+	errs := make([]error, len(pages))
+	next := 0 // Index of the first tile of the page in images.
+	for i, tiles := range pages {
+		end := next + len(tiles)
+		for _, err := range annotationErrs[next:end] {
+			if err != nil && !errors.Is(err, errNoText) {
+				errs[i] = err
+				break
+			}
+		}
+		if errs[i] == nil {
+			blocks[i], errs[i] = mergeTiles(tiles, annotations[next:end])
+		}
+		next = end
+	}
+	return blocks, errs
+}
+
+// BatchAnnotate detects the text of several images with one request per MaxBatchSize images.
+// The requests are sent at the same time, as far as the limiter of the client allows.
+// It returns the annotation and the error of every image. The error is errNoText for images without text.
+// Images rejected with a retryable error inside a successful response, such as an exhausted quota, are sent again on their own.
+func (c *Client) BatchAnnotate(ctx context.Context, images []*image.RGBA) ([]*pb.TextAnnotation, []error) {
+	annotations := make([]*pb.TextAnnotation, len(images))
+	errs := make([]error, len(images))
+
+	client, err := c.annotator()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return annotations, errs
+	}
+
+	// Every request writes the results of its own images.
+	var wg sync.WaitGroup
+	send := func(start int, requests []*pb.AnnotateImageRequest) {
+		log.Debugf("Detecting text of %d images in one request", len(requests))
+		// Indices of the requests still to be sent. Retries only send the images which failed with a retryable error.
+		pending := make([]int, len(requests))
+		for i := range pending {
+			pending[i] = i
+		}
+		err := c.limit.Do(ctx, throttle.Vision, len(requests), func() error {
+			batch := make([]*pb.AnnotateImageRequest, len(pending))
+			for k, i := range pending {
+				batch[k] = requests[i]
+			}
+			resp, err := client.BatchAnnotateImages(ctx, &pb.BatchAnnotateImagesRequest{Requests: batch})
+			if err != nil {
+				return err
+			}
+
+			var retry []int
+			var retryErr error
+			for k, r := range resp.Responses {
+				i := pending[k]
+				switch {
+				case r.GetError() != nil:
+					log.Errorf("BatchAnnotateImages: image %d: %v", start+i, r.GetError().GetMessage())
+					errs[start+i] = status.ErrorProto(r.GetError())
+					if throttle.Retryable(errs[start+i]) {
+						retry, retryErr = append(retry, i), errs[start+i]
+					}
+				case r.GetFullTextAnnotation() == nil:
+					errs[start+i] = errNoText
+				default:
+					log.WithField("text", r.GetFullTextAnnotation().Text).Info("Detected Text")
+					annotations[start+i] = r.GetFullTextAnnotation()
+				}
+			}
+			pending = retry
+			return retryErr
+		})
+		if err != nil {
+			log.Errorf("BatchAnnotateImages: %v", err)
+			for _, i := range pending {
+				errs[start+i] = err
+			}
+		}
+	}
+
+	var requests []*pb.AnnotateImageRequest
+	start, size := 0, 0
+	for i, img := range images {
+		content := ReaderFromImage(img)
+		if len(requests) == MaxBatchSize || (len(requests) > 0 && size+content.Len() > maxBatchBytes) {
+			wg.Add(1)
+			go func(start int, requests []*pb.AnnotateImageRequest) {
+				defer wg.Done()
+				send(start, requests)
+			}(start, requests)
+			requests, start, size = nil, i, 0
+		}
+
+		data := make([]byte, content.Len())
+		content.Read(data)
+		requests = append(requests, &pb.AnnotateImageRequest{
+			Image:        &pb.Image{Content: data},
+			Features:     []*pb.Feature{{Type: pb.Feature_DOCUMENT_TEXT_DETECTION}},
+			ImageContext: &pb.ImageContext{LanguageHints: []string{"ja"}},
+		})
+		size += len(data)
+	}
+	if len(requests) > 0 {
+		send(start, requests)
+	}
+	wg.Wait()
+	return annotations, errs
+}
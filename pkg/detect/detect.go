@@ -8,13 +8,20 @@ import (
 	"image/color"
 	_ "image/jpeg"
 	"image/png"
+	"os/user"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	vision "cloud.google.com/go/vision/apiv1"
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/throttle"
 	log "github.com/sirupsen/logrus"
 	pb "google.golang.org/genproto/googleapis/cloud/vision/v1"
 )
 
+// borderColors are the colors of the blocks, used in turn. They are set by the theme of the viewer.
 var borderColors = []color.NRGBA{
 	{R: 255, A: 255},         // Red
 	{G: 255, A: 255},         // Green
@@ -27,16 +34,68 @@ var borderColors = []color.NRGBA{
 type TextBlock struct {
 	Text       string
 	Translated string
+	Override   *Override // Translation typed in by a person, nil if the block has not been edited.
+	Hidden     bool      // Hidden blocks are kept, but not displayed on the page.
 	Vertices   []*pb.Vertex
 	Color      color.NRGBA
 }
 
+// Override is a human translation of a block, kept separately from the machine translation.
+type Override struct {
+	Text   string
+	Author string
+	Edited time.Time
+}
+
+// NewOverride creates an override with the given text, written by the current user.
+func NewOverride(txt string) *Override {
+	author := "unknown"
+	if u, err := user.Current(); err == nil {
+		author = u.Username
+	} else {
+		log.Warningf("Unable to get current user: %v", err)
+	}
+	return &Override{
+		Text:   txt,
+		Author: author,
+		Edited: time.Now(),
+	}
+}
+
+// Final returns the human translation of the block if there is one, otherwise the machine translation.
+// Anything presenting or exporting translations should use this instead of Translated.
+func (b TextBlock) Final() string {
+	if b.Override != nil {
+		return b.Override.Text
+	}
+	return b.Translated
+}
+
 var errInvalidVisionPath = errors.New(`path given for Vision API service account key is invalid. Please run the "manga-translator-setup" application to fix it`)
 
-func GetAnnotation(img *image.RGBA) (*pb.TextAnnotation, error) {
-	ctx := context.Background()
+var errNoText = errors.New("no text found")
+
+// Client detects text with the Vision API. It is safe for concurrent use.
+type Client struct {
+	limit *throttle.Limiter
 
-	client, err := vision.NewImageAnnotatorClient(ctx)
+	mu     sync.Mutex
+	vision *vision.ImageAnnotatorClient // Created on first use.
+}
+
+// NewClient returns a client which connects to the Vision API on first use. Requests are sent through the given limiter.
+func NewClient(limit *throttle.Limiter) *Client {
+	return &Client{limit: limit}
+}
+
+func (c *Client) annotator() (*vision.ImageAnnotatorClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.vision != nil {
+		return c.vision, nil
+	}
+
+	client, err := vision.NewImageAnnotatorClient(context.Background())
 	if err != nil {
 		log.Errorf("NewImageAnnotatorClient: %v", err)
 		if strings.HasPrefix(err.Error(),
@@ -45,6 +104,28 @@ func GetAnnotation(img *image.RGBA) (*pb.TextAnnotation, error) {
 		}
 		return nil, err
 	}
+	c.vision = client
+	return client, nil
+}
+
+// Close closes the connection to the Vision API.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.vision == nil {
+		return nil
+	}
+	err := c.vision.Close()
+	c.vision = nil
+	return err
+}
+
+// GetAnnotation detects the text of the image with the Vision API. The request is aborted when ctx is cancelled.
+func (c *Client) GetAnnotation(ctx context.Context, img *image.RGBA) (*pb.TextAnnotation, error) {
+	client, err := c.annotator()
+	if err != nil {
+		return nil, err
+	}
 
 	reader := ReaderFromImage(img)
 	visionImg, err := vision.NewImageFromReader(reader)
@@ -53,7 +134,11 @@ func GetAnnotation(img *image.RGBA) (*pb.TextAnnotation, error) {
 		return nil, err
 	}
 
-	annotation, err := client.DetectDocumentText(ctx, visionImg, &pb.ImageContext{LanguageHints: []string{"ja"}})
+	var annotation *pb.TextAnnotation
+	err = c.limit.Do(ctx, throttle.Vision, 1, func() error {
+		annotation, err = client.DetectDocumentText(ctx, visionImg, &pb.ImageContext{LanguageHints: []string{"ja"}})
+		return err
+	})
 	if err != nil {
 		log.Errorf("DetectDocumentText: %v", err)
 		return nil, err
@@ -61,7 +146,7 @@ func GetAnnotation(img *image.RGBA) (*pb.TextAnnotation, error) {
 
 	if annotation == nil {
 		log.Info("No text found")
-		return nil, errors.New("no text found")
+		return nil, errNoText
 	} else {
 		log.WithField("text", annotation.Text).Info("Detected Text")
 		return annotation, nil
@@ -88,13 +173,203 @@ func OrganizeAnnotation(annotation *pb.TextAnnotation) []TextBlock {
 			blockList = append(blockList, TextBlock{
 				Text:     b,
 				Vertices: block.BoundingBox.Vertices,
-				Color:    borderColors[i%len(borderColors)],
+				Color:    BlockColor(i),
 			})
 		}
 	}
 	return blockList
 }
 
+// DetectTiles detects the text of every tile and returns the blocks in the coordinates of the full image, from top to bottom.
+// Blocks found in the overlap of two neighbouring tiles are only kept once.
+func (c *Client) DetectTiles(ctx context.Context, tiles []imageW.Tile) ([]TextBlock, error) {
+	annotations := make([]*pb.TextAnnotation, len(tiles))
+	for i, tile := range tiles {
+		log.Debugf("Detecting text in tile %d/%d at %v", i+1, len(tiles), tile.Offset)
+		annotation, err := c.GetAnnotation(ctx, tile.Image)
+		if err != nil && !errors.Is(err, errNoText) {
+			return nil, err
+		}
+		annotations[i] = annotation
+	}
+	return mergeTiles(tiles, annotations)
+}
+
+// mergeTiles returns the blocks of the annotations of the tiles, nil for tiles without text, in the coordinates of the full image.
+func mergeTiles(tiles []imageW.Tile, annotations []*pb.TextAnnotation) ([]TextBlock, error) {
+	var blocks []TextBlock
+	var prevStart, prevEnd int // Range of the blocks of the previous tile.
+
+	for i, tile := range tiles {
+		annotation := annotations[i]
+		if annotation == nil {
+			prevStart, prevEnd = len(blocks), len(blocks)
+			continue
+		}
+
+		start := len(blocks)
+		for _, block := range OrganizeAnnotation(annotation) {
+			block.Vertices = offsetVertices(block.Vertices, tile.Offset)
+
+			dup := -1
+			for j := prevStart; j < prevEnd; j++ {
+				if isDuplicate(blocks[j], block) {
+					dup = j
+					break
+				}
+			}
+			if dup < 0 {
+				blocks = append(blocks, block)
+				continue
+			}
+			// Text cut by the edge of a tile is only partially detected, so the larger block is the complete one.
+			log.WithField("text", block.Text).Debug("Duplicate block in tile overlap")
+			if blockArea(block) > blockArea(blocks[dup]) {
+				blocks[dup] = block
+			}
+		}
+		prevStart, prevEnd = start, len(blocks)
+	}
+
+	if len(blocks) == 0 {
+		return nil, errNoText
+	}
+	Recolor(blocks)
+	return blocks, nil
+}
+
+func offsetVertices(vertices []*pb.Vertex, offset image.Point) []*pb.Vertex {
+	moved := make([]*pb.Vertex, len(vertices))
+	for i, v := range vertices {
+		moved[i] = &pb.Vertex{X: v.X + int32(offset.X), Y: v.Y + int32(offset.Y)}
+	}
+	return moved
+}
+
+// blockRect returns the bounding rectangle of the vertices of the block.
+func blockRect(b TextBlock) image.Rectangle {
+	var r image.Rectangle
+	for i, v := range b.Vertices {
+		x, y := int(v.X), int(v.Y)
+		if i == 0 || x < r.Min.X {
+			r.Min.X = x
+		}
+		if i == 0 || y < r.Min.Y {
+			r.Min.Y = y
+		}
+		if i == 0 || x > r.Max.X {
+			r.Max.X = x
+		}
+		if i == 0 || y > r.Max.Y {
+			r.Max.Y = y
+		}
+	}
+	return r
+}
+
+func blockArea(b TextBlock) int {
+	size := blockRect(b).Size()
+	return size.X * size.Y
+}
+
+// isDuplicate returns true if the blocks cover mostly the same area.
+func isDuplicate(a, b TextBlock) bool {
+	overlap := blockRect(a).Intersect(blockRect(b)).Size()
+	smaller := blockArea(a)
+	if area := blockArea(b); area < smaller {
+		smaller = area
+	}
+	return smaller > 0 && overlap.X*overlap.Y*2 >= smaller
+}
+
+// Filter removes unwanted blocks, such as page numbers, watermarks and credits.
+type Filter struct {
+	Patterns  []*regexp.Regexp
+	MinWidth  int
+	MinHeight int
+}
+
+// NewFilter compiles the given patterns into a filter. Invalid patterns are skipped.
+func NewFilter(patterns []string, minWidth, minHeight int) Filter {
+	f := Filter{MinWidth: minWidth, MinHeight: minHeight}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Warningf("Invalid filter pattern %q: %v", pattern, err)
+			continue
+		}
+		f.Patterns = append(f.Patterns, re)
+	}
+	return f
+}
+
+// Apply returns the blocks which are large enough and do not match any of the patterns.
+func (f Filter) Apply(blocks []TextBlock) []TextBlock {
+	var kept []TextBlock
+	for _, block := range blocks {
+		if !f.keep(block) {
+			log.WithField("text", block.Text).Debug("Filtered block")
+			continue
+		}
+		kept = append(kept, block)
+	}
+	return kept
+}
+
+func (f Filter) keep(block TextBlock) bool {
+	// The bounding rectangle is used, as the vertices of rotated text are not in the same order.
+	if len(block.Vertices) > 0 {
+		size := blockRect(block).Size()
+		if size.X < f.MinWidth || size.Y < f.MinHeight {
+			return false
+		}
+	}
+	for _, re := range f.Patterns {
+		if re.MatchString(block.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeAnnotation joins all text of the annotation into a single block covering the given area of the page.
+// The index is used to pick the color of the block.
+func MergeAnnotation(annotation *pb.TextAnnotation, area image.Rectangle, i int) TextBlock {
+	var txt string
+	for _, block := range OrganizeAnnotation(annotation) {
+		txt += block.Text
+	}
+	return TextBlock{
+		Text: txt,
+		Vertices: []*pb.Vertex{
+			{X: int32(area.Min.X), Y: int32(area.Min.Y)},
+			{X: int32(area.Max.X), Y: int32(area.Min.Y)},
+			{X: int32(area.Max.X), Y: int32(area.Max.Y)},
+			{X: int32(area.Min.X), Y: int32(area.Max.Y)},
+		},
+		Color: BlockColor(i),
+	}
+}
+
+// BlockColor returns the border color of the block at the given index.
+func BlockColor(i int) color.NRGBA {
+	return borderColors[i%len(borderColors)]
+}
+
+// SetBlockColors replaces the colors of new blocks. Empty lists are ignored.
+func SetBlockColors(colors []color.NRGBA) {
+	if len(colors) > 0 {
+		borderColors = colors
+	}
+}
+
+// Recolor sets the color of every block by its index, so that blocks from the cache match the current colors.
+func Recolor(blocks []TextBlock) {
+	for i := range blocks {
+		blocks[i].Color = BlockColor(i)
+	}
+}
+
 func ReaderFromImage(img *image.RGBA) *bytes.Reader {
 	buff := new(bytes.Buffer)
 
@@ -0,0 +1,94 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// captureCommand is a screenshot helper which lets the user select a region of the screen and saves it to the file $1.
+type captureCommand struct {
+	tools   []string // Programs which must be installed.
+	command string   // Run with sh.
+}
+
+var (
+	waylandCommands = []captureCommand{
+		{[]string{"grim", "slurp"}, `grim -g "$(slurp)" "$1"`},
+		{[]string{"spectacle"}, `spectacle --region --background --nonotify --output "$1"`},
+		{[]string{"gnome-screenshot"}, `gnome-screenshot --area --file="$1"`},
+	}
+	x11Commands = []captureCommand{
+		{[]string{"screencapture"}, `screencapture -i "$1"`}, // macOS
+		{[]string{"maim"}, `maim --select "$1"`},
+		{[]string{"scrot"}, `scrot --select "$1"`},
+		{[]string{"spectacle"}, `spectacle --region --background --nonotify --output "$1"`},
+		{[]string{"gnome-screenshot"}, `gnome-screenshot --area --file="$1"`},
+		{[]string{"import"}, `import "$1"`}, // ImageMagick
+	}
+)
+
+// Capture lets the user select a region of the screen with a screenshot helper and opens it.
+// The command is run with sh and must save the region as an image to the file given as $1.
+// If it is empty, the first installed helper known to work on the current desktop is used.
+func Capture(command string) (TranslatorImage, error) {
+	if runtime.GOOS == "windows" {
+		return TranslatorImage{}, errors.New(`screen capture is not supported on Windows, copy a region with Win+Shift+S and use "-watch-clip" instead`)
+	}
+
+	if command == "" {
+		var err error
+		command, err = findCaptureCommand()
+		if err != nil {
+			return TranslatorImage{}, err
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "mtl-capture")
+	if err != nil {
+		return TranslatorImage{}, err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "capture.png")
+
+	log.Debugf("Capturing screen region with: %v", command)
+	out, err := exec.Command("sh", "-c", command, "sh", file).CombinedOutput()
+	if err != nil {
+		return TranslatorImage{}, fmt.Errorf("screen capture failed: %v: %s", err, out)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return TranslatorImage{}, errors.New("screen capture was canceled")
+	}
+
+	img, err := Load(file, false, false)
+	// The file is removed, so the capture cannot be opened again like a clipboard image.
+	img.Source = ""
+	return img, err
+}
+
+func findCaptureCommand() (string, error) {
+	commands := x11Commands
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = waylandCommands
+	}
+
+	for _, c := range commands {
+		installed := true
+		for _, tool := range c.tools {
+			if _, err := exec.LookPath(tool); err != nil {
+				installed = false
+				break
+			}
+		}
+		if installed {
+			return c.command, nil
+		}
+	}
+	return "", errors.New(`no screenshot helper found, install one such as "grim" and "slurp", "maim" or "gnome-screenshot", or set "capture.command" in the config`)
+}
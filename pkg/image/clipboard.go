@@ -0,0 +1,36 @@
+package image
+
+import (
+	"bytes"
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"golang.design/x/clipboard"
+)
+
+// WatchClipboard sends every image which is copied to the clipboard until the context is canceled.
+// The image that is in the clipboard when watching starts is not sent.
+func WatchClipboard(ctx context.Context) (<-chan TranslatorImage, error) {
+	if err := clipboard.Init(); err != nil {
+		return nil, err
+	}
+
+	images := make(chan TranslatorImage)
+	go func() {
+		defer close(images)
+		for data := range clipboard.Watch(ctx, clipboard.FmtImage) {
+			log.Info("New image copied to the clipboard")
+			img, err := Decode(bytes.NewReader(data))
+			if err != nil {
+				log.Warningf("Unable to open copied image: %v", err)
+				continue
+			}
+			select {
+			case images <- img:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return images, nil
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
-	"hash"
+	"errors"
+	"fmt"
 	"image"
 	"image/draw"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"golang.design/x/clipboard"
@@ -25,79 +28,80 @@ type TranslatorImage struct {
 	Image      *image.RGBA
 	Hash       string
 	Dimensions Dimensions
+	Source     string // Path or URL the image was opened from, empty for clipboard images.
+	FromURL    bool   // Is true if Source is a URL.
 	size       int
 }
 
+// Open opens an image like Load and exits if it cannot be opened.
 func Open(file string, url, clip bool) TranslatorImage {
-	var img image.Image
-	var h hash.Hash
-	var size int
+	img, err := Load(file, url, clip)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return img
+}
 
-	if clip {
-		var err error
+// Load opens an image from a local file, a URL or the clipboard.
+func Load(file string, url, clip bool) (TranslatorImage, error) {
+	var r io.Reader
 
-		err = clipboard.Init()
+	if clip {
+		err := clipboard.Init()
 		if err != nil {
-			log.Fatal(err)
+			return TranslatorImage{}, err
 		}
 
 		imgByte := clipboard.Read(clipboard.FmtImage)
 		if imgByte == nil {
-			log.Fatal("No image found in clipboard")
-		}
-
-		var buf bytes.Buffer
-		tee := io.TeeReader(bytes.NewReader(imgByte), &buf)
-		img, _, err = image.Decode(tee)
-		size = buf.Len()
-		if err != nil {
-			log.Fatalf("Image decode error: %v", err)
-		}
-
-		h = sha256.New()
-		if _, err := io.Copy(h, &buf); err != nil {
-			log.Fatalf("Hash error: %v", err)
+			return TranslatorImage{}, errors.New("no image found in clipboard")
 		}
+		r = bytes.NewReader(imgByte)
 	} else if url {
 		resp, err := http.Get(file)
 		if err != nil {
-			log.Fatal(err)
+			return TranslatorImage{}, err
 		}
 		defer resp.Body.Close()
-
-		var buf bytes.Buffer
-		tee := io.TeeReader(resp.Body, &buf)
-
-		img, _, err = image.Decode(tee)
-		size = buf.Len()
-		if err != nil {
-			log.Fatalf("Image decode error: %v", err)
-		}
-
-		h = sha256.New()
-		if _, err := io.Copy(h, &buf); err != nil {
-			log.Fatalf("Hash error: %v", err)
-		}
+		r = resp.Body
 	} else {
 		f, err := os.Open(filepath.ToSlash(file))
 		if err != nil {
-			log.Fatal(err)
+			return TranslatorImage{}, err
 		}
 		defer f.Close()
+		r = f
+	}
 
-		var buf bytes.Buffer
-		tee := io.TeeReader(f, &buf)
+	newImg, err := Decode(r)
+	if err != nil {
+		return newImg, err
+	}
+	// Clipboard images have no source.
+	if !clip && url {
+		newImg.Source = file
+		newImg.FromURL = true
+	} else if !clip {
+		// Absolute paths keep working if the application is started from another directory.
+		newImg.Source, _ = filepath.Abs(file)
+	}
+	return newImg, nil
+}
 
-		img, _, err = image.Decode(tee)
-		size = buf.Len()
-		if err != nil {
-			log.Fatalf("Image decode error: %v", err)
-		}
+// Decode reads an image and hashes its encoded data.
+func Decode(r io.Reader) (TranslatorImage, error) {
+	var buf bytes.Buffer
+	tee := io.TeeReader(r, &buf)
 
-		h = sha256.New()
-		if _, err := io.Copy(h, &buf); err != nil {
-			log.Fatalf("Hash error: %v", err)
-		}
+	img, _, err := image.Decode(tee)
+	if err != nil {
+		return TranslatorImage{}, fmt.Errorf("image decode error: %w", err)
+	}
+	size := buf.Len()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, &buf); err != nil {
+		return TranslatorImage{}, fmt.Errorf("hash error: %w", err)
 	}
 
 	hashInBytes := h.Sum(nil)
@@ -113,7 +117,41 @@ func Open(file string, url, clip bool) TranslatorImage {
 	log.Debugf("Hash: %v", hashStr)
 	log.Debugf("Image Dimensions: %v", dims)
 	newImg.resize()
-	return newImg
+	return newImg, nil
+}
+
+// imageExtensions are the file types which are opened from folders.
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true}
+
+// IsImage returns true if the file has the extension of an image which can be opened.
+func IsImage(path string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Paths returns the given path if it is a file, or the images in it sorted by name if it is a folder.
+func Paths(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && IsImage(entry.Name()) {
+			paths = append(paths, filepath.Join(path, entry.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no images found in %v", path)
+	}
+	return paths, nil
 }
 
 func convertToRGBA(img image.Image) *image.RGBA {
@@ -129,6 +167,11 @@ func (img *TranslatorImage) resize() {
 	if img.size <= desiredSize {
 		return
 	}
+	// Tall images are split into tiles for text detection instead, so they keep their resolution.
+	if img.IsTall() {
+		log.Debug("Not resizing tall image")
+		return
+	}
 
 	log.Info("Resizing Image")
 	ratio := img.size / desiredSize
@@ -141,6 +184,24 @@ func (img *TranslatorImage) resize() {
 	log.Debugf("New image dimensions: %v", img.Dimensions)
 }
 
+// Crop returns a copy of the given area of the image. The area is limited to the bounds of the image.
+func (img TranslatorImage) Crop(area image.Rectangle) *image.RGBA {
+	area = area.Intersect(img.Image.Bounds())
+	return convertToRGBA(img.Image.SubImage(area))
+}
+
+// Thumbnail returns a copy of the image scaled down so that its longest side is maxDim pixels.
+func (img TranslatorImage) Thumbnail(maxDim float32) *image.RGBA {
+	ratio := GetRatio(img.Dimensions, maxDim)
+	if ratio > 1 {
+		ratio = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, int(float32(img.Dimensions.Width)*ratio), int(float32(img.Dimensions.Height)*ratio)))
+	drawX.ApproxBiLinear.Scale(dst, dst.Rect, img.Image, img.Image.Bounds(), draw.Src, nil)
+	return dst
+}
+
 func getDimensions(img image.Image) Dimensions {
 	bounds := img.Bounds()
 	return Dimensions{
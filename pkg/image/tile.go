@@ -0,0 +1,45 @@
+package image
+
+import (
+	"image"
+)
+
+const (
+	// TileHeight is the height of the tiles tall images are split into for text detection.
+	TileHeight = 4000
+	// TileOverlap is the number of rows shared by neighbouring tiles, so that text cut by the edge of one tile is whole in the other.
+	TileOverlap = 500
+)
+
+// Tile is a part of an image that is sent to text detection on its own.
+type Tile struct {
+	Image  *image.RGBA
+	Offset image.Point // Position of the tile in the full image.
+}
+
+// IsTall returns true if the image is a long strip, such as a webtoon episode, which is split into tiles for text detection.
+func (img TranslatorImage) IsTall() bool {
+	return img.Dimensions.Height > TileHeight && img.Dimensions.Height > 2*img.Dimensions.Width
+}
+
+// Tiles splits the image into overlapping tiles from top to bottom.
+// Images which are not tall are returned as a single tile.
+func (img TranslatorImage) Tiles() []Tile {
+	if !img.IsTall() {
+		return []Tile{{Image: img.Image}}
+	}
+
+	var tiles []Tile
+	bounds := img.Image.Bounds()
+	for y := bounds.Min.Y; ; y += TileHeight - TileOverlap {
+		area := image.Rect(bounds.Min.X, y, bounds.Max.X, y+TileHeight)
+		tiles = append(tiles, Tile{
+			Image:  img.Crop(area),
+			Offset: area.Intersect(bounds).Min,
+		})
+		if area.Max.Y >= bounds.Max.Y {
+			break
+		}
+	}
+	return tiles
+}
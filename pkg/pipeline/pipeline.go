@@ -0,0 +1,245 @@
+// Package pipeline detects and translates the text of a page like the viewer does, so that other programs can reuse it.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/cache"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/throttle"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/translate"
+)
+
+// ErrBlankConfig is returned if the config has not been created yet.
+var ErrBlankConfig = errors.New(`your config is either blank or doesn't exist, run the "manga-translator-setup" application to create one`)
+
+// ErrNoConfig is returned if the options do not have a config.
+var ErrNoConfig = errors.New("pipeline: no config given")
+
+// Clients holds the connections to the APIs.
+type Clients struct {
+	Detect    *detect.Client
+	Translate *translate.Client
+}
+
+// NewClients returns the clients for the services selected in the given config. Their requests share the limits set in the config.
+//
+// The clients are safe for concurrent use, so one set is meant to be shared by all pages, keeping the connections open between them.
+// The connections are made on first use, so that missing or invalid keys are reported for every page instead of preventing the start,
+// and they are not tied to the context of any single request.
+func NewClients(cfg *config.File) *Clients {
+	limit := throttle.New(cfg)
+	return &Clients{
+		Detect:    detect.NewClient(limit),
+		Translate: translate.NewClient(cfg, limit),
+	}
+}
+
+// Close closes the connections to the APIs. The clients must not be used afterwards.
+func (c *Clients) Close() error {
+	return errors.Join(c.Detect.Close(), c.Translate.Close())
+}
+
+// Options configures the processing of a page.
+type Options struct {
+	// Config selects the services and their settings. It is required.
+	Config *config.File
+
+	// Clients are used for the requests. If it is nil, clients are created for this call only.
+	Clients *Clients
+
+	// Progress is called with a short description of every step, if it is set.
+	Progress func(status string)
+}
+
+// Result holds the text blocks of a page.
+type Result struct {
+	Blocks []detect.TextBlock
+	Cached bool // Is true if the blocks and their translation were found in the cache.
+}
+
+// Process detects and translates the text of the image with the services selected in the config.
+// The text and translation of pages which were processed before are read from the cache, and new results are added to it.
+// If the translation fails, the result still holds the detected blocks, with the error message as their translation.
+// If the cache cannot be read or written, a *cache.Error is returned.
+// The requests are aborted when ctx is cancelled, and nothing is cached.
+func Process(ctx context.Context, img imageW.TranslatorImage, opts Options) (Result, error) {
+	cfg := opts.Config
+	if cfg == nil {
+		return Result{}, ErrNoConfig
+	} else if cfg.IsBlank() {
+		return Result{}, ErrBlankConfig
+	}
+	clients, progress, done := opts.setup()
+	defer done()
+
+	blocks, translateOnly, err := cache.Check(img.Hash, cfg.Translation.SelectedService)
+	if err != nil {
+		return Result{}, err
+	}
+	if blocks != nil && !translateOnly {
+		return Result{Blocks: blocks, Cached: true}, nil
+	}
+
+	if !translateOnly {
+		progress(`Detecting text...`)
+
+		// Tall images are detected in several tiles.
+		detected, err := clients.Detect.DetectTiles(ctx, img.Tiles())
+		if err != nil {
+			return Result{}, err
+		}
+		blocks = opts.filter().Apply(detected)
+	}
+
+	progress(`Translating text...`)
+	return translatePage(ctx, cfg, clients, img.Hash, blocks)
+}
+
+// PageResult is the result of one page of a batch.
+type PageResult struct {
+	Result
+	Err error
+}
+
+// ProcessBatch processes several pages like Process, but detects the text of all pages which are not in the cache
+// with as few requests as possible. This is faster for a whole chapter than processing the pages one by one.
+// The results are keyed by the hash of the image.
+func ProcessBatch(ctx context.Context, images []imageW.TranslatorImage, opts Options) map[string]PageResult {
+	results := make(map[string]PageResult, len(images))
+	cfg := opts.Config
+	if cfg == nil || cfg.IsBlank() {
+		err := ErrBlankConfig
+		if cfg == nil {
+			err = ErrNoConfig
+		}
+		for _, img := range images {
+			results[img.Hash] = PageResult{Err: err}
+		}
+		return results
+	}
+	clients, progress, done := opts.setup()
+	defer done()
+
+	// Blocks of the pages which still need to be translated.
+	pending := make(map[string][]detect.TextBlock)
+	var detectImages []imageW.TranslatorImage
+	for _, img := range images {
+		if _, ok := results[img.Hash]; ok {
+			continue
+		} else if _, ok := pending[img.Hash]; ok {
+			continue
+		}
+
+		blocks, translateOnly, err := cache.Check(img.Hash, cfg.Translation.SelectedService)
+		switch {
+		case err != nil:
+			results[img.Hash] = PageResult{Err: err}
+		case blocks != nil && !translateOnly:
+			results[img.Hash] = PageResult{Result: Result{Blocks: blocks, Cached: true}}
+		case translateOnly:
+			pending[img.Hash] = blocks
+		default:
+			// Marks the page as seen, so that duplicates are only detected once.
+			pending[img.Hash] = nil
+			detectImages = append(detectImages, img)
+		}
+	}
+
+	if len(detectImages) > 0 {
+		progress(`Detecting text...`)
+
+		pages := make([][]imageW.Tile, len(detectImages))
+		for i, img := range detectImages {
+			pages[i] = img.Tiles()
+		}
+		detected, errs := clients.Detect.DetectPages(ctx, pages)
+
+		filter := opts.filter()
+		for i, img := range detectImages {
+			if errs[i] != nil {
+				delete(pending, img.Hash)
+				results[img.Hash] = PageResult{Err: errs[i]}
+				continue
+			}
+			pending[img.Hash] = filter.Apply(detected[i])
+		}
+	}
+
+	if len(pending) > 0 {
+		progress(`Translating text...`)
+	}
+	// The pages are translated at the same time, as far as the limits of the clients allow.
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for hash, blocks := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := translatePage(ctx, cfg, clients, hash, blocks)
+			mu.Lock()
+			results[hash] = PageResult{Result: res, Err: err}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// setup returns the clients and progress function of the options, and a function to release them once the pages are processed.
+func (opts Options) setup() (clients *Clients, progress func(string), done func()) {
+	clients, done = opts.Clients, func() {}
+	if clients == nil {
+		clients = NewClients(opts.Config)
+		done = func() { clients.Close() }
+	}
+	progress = opts.Progress
+	if progress == nil {
+		progress = func(string) {}
+	}
+	return clients, progress, done
+}
+
+func (opts Options) filter() detect.Filter {
+	cfg := opts.Config
+	return detect.NewFilter(cfg.Filter.Patterns, cfg.Filter.MinWidth, cfg.Filter.MinHeight)
+}
+
+// translatePage translates the detected blocks of a page and adds them to the cache.
+// If they cannot be cached, the translated blocks are returned with the *cache.Error.
+func translatePage(ctx context.Context, cfg *config.File, clients *Clients, hash string, blocks []detect.TextBlock) (Result, error) {
+	// Pages without text, or whose text was all filtered out, have nothing to translate.
+	if len(blocks) == 0 {
+		blocks = []detect.TextBlock{}
+		if err := cache.Add(hash, cfg.Translation.SelectedService, blocks); err != nil {
+			log.Errorf("Unable to cache page %v: %v", hash, err)
+			return Result{Blocks: blocks}, err
+		}
+		return Result{Blocks: blocks}, nil
+	}
+
+	var original []string
+	for _, block := range blocks {
+		original = append(original, block.Text)
+	}
+	translated, err := clients.Translate.Translate(ctx, original)
+	for i, txt := range translated {
+		blocks[i].Translated = txt
+	}
+	if err != nil {
+		log.Errorf("Unable to translate page %v: %v", hash, err)
+		return Result{Blocks: blocks}, err
+	}
+
+	if err := cache.Add(hash, cfg.Translation.SelectedService, blocks); err != nil {
+		log.Errorf("Unable to cache page %v: %v", hash, err)
+		return Result{Blocks: blocks}, err
+	}
+	return Result{Blocks: blocks}, nil
+}
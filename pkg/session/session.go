@@ -0,0 +1,57 @@
+package session
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+)
+
+// File is the mtl/mtl-session.yml structure. It records the state of the viewer, so that reading can be resumed later.
+type File struct {
+	Pages      []Page  `yaml:"pages"`
+	Page       int     `yaml:"page"`           // Index of the current page.
+	SplitRatio float32 `yaml:"splitRatio"`     // Ratio of the split between the image and the text panel.
+	Width      float32 `yaml:"width"`          // Window width in dp.
+	Height     float32 `yaml:"height"`         // Window height in dp.
+	View       string  `yaml:"view,omitempty"` // Name of the view mode.
+}
+
+// Page is a page that can be opened again. Images from the clipboard are not recorded.
+type Page struct {
+	Source string `yaml:"source"`
+	URL    bool   `yaml:"url,omitempty"`
+}
+
+func path() string {
+	return filepath.Join(config.Path(), "mtl-session.yml")
+}
+
+// Save writes the given session to "mtl/mtl-session.yml", replacing the previous one.
+func Save(s File) {
+	d, err := yaml.Marshal(&s)
+	if err != nil {
+		log.Errorf("Session marshal failed: %v", err)
+		return
+	}
+
+	if err := ioutil.WriteFile(path(), d, 0644); err != nil {
+		log.Errorf("Session write failed: %v", err)
+	}
+}
+
+// Load reads the last saved session.
+func Load() (File, error) {
+	var s File
+
+	d, err := ioutil.ReadFile(path())
+	if err != nil {
+		return s, err
+	}
+
+	err = yaml.Unmarshal(d, &s)
+	return s, err
+}
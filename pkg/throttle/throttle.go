@@ -0,0 +1,149 @@
+// Package throttle limits the requests to the APIs, so that large batches of pages do not exceed the quotas of the services.
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+)
+
+// Services with their own rate limit.
+const (
+	Vision      = "vision"
+	Translation = "translation"
+)
+
+const (
+	defaultConcurrency = 4
+	defaultRetries     = 3
+
+	firstBackoff = time.Second
+	maxBackoff   = 30 * time.Second
+)
+
+// Limiter runs the requests of all pages with a bounded number of workers and a rate limit per service,
+// and retries requests which were rejected because of a quota or a temporary server error. It is safe for concurrent use.
+type Limiter struct {
+	workers chan struct{} // Holds a value for every request in progress.
+	rates   map[string]*rate.Limiter
+	retries int
+}
+
+// New returns a limiter with the limits set in the config.
+func New(cfg *config.File) *Limiter {
+	limits := cfg.Limits
+
+	concurrency := limits.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	retries := limits.Retries
+	if retries == 0 {
+		retries = defaultRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+
+	return &Limiter{
+		workers: make(chan struct{}, concurrency),
+		rates: map[string]*rate.Limiter{
+			Vision:      perMinute(limits.VisionPerMinute),
+			Translation: perMinute(limits.TranslationPerMinute),
+		},
+		retries: retries,
+	}
+}
+
+// perMinute returns a rate limit of n units per minute, which is unlimited if n is not positive.
+func perMinute(n int) *rate.Limiter {
+	if n <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(n)), n)
+}
+
+// Do runs the request f, which uses the given number of units of the rate limit of the service, such as the images of a batch.
+// Requests which fail with an error accepted by Retryable are retried, waiting longer before every retry.
+// It returns the error of the last attempt.
+func (l *Limiter) Do(ctx context.Context, service string, units int, f func() error) error {
+	backoff := firstBackoff
+	for attempt := 0; ; attempt++ {
+		err := l.do(ctx, service, units, f)
+		if err == nil || attempt >= l.retries || !Retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		log.Warningf("Request to %v failed, retrying in %v: %v", service, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+func (l *Limiter) do(ctx context.Context, service string, units int, f func() error) error {
+	// The rate is waited for before taking a worker, so that requests of a slow service do not hold the workers of the others.
+	if r, ok := l.rates[service]; ok {
+		// A request larger than the limit of a minute waits for the whole minute.
+		if r.Limit() != rate.Inf && units > r.Burst() {
+			units = r.Burst()
+		}
+		if err := r.WaitN(ctx, units); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case l.workers <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-l.workers }()
+	return f()
+}
+
+// HTTPError is an error response of a service which is called over plain HTTP.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.Status)
+}
+
+// Retryable returns true if the error is a rejection because of a quota (HTTP 429) or a temporary server error (HTTP 5xx),
+// so that the request may succeed if it is sent again later.
+func Retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return retryableStatus(httpErr.StatusCode)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.Code)
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.ResourceExhausted, codes.Unavailable, codes.Internal:
+			return true
+		}
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
@@ -1,6 +1,7 @@
 package translate
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
@@ -9,6 +10,8 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/throttle"
 )
 
 type DeepLResponse struct {
@@ -33,7 +36,7 @@ func TranslationError(message string, txt []string) []string {
 }
 
 // DeepLTranslate translates the given slice of strings from source language to target language using the DeepL API.
-func DeepLTranslate(txt []string, source, target, apiKey string) ([]string, error) {
+func DeepLTranslate(ctx context.Context, txt []string, source, target, apiKey string) ([]string, error) {
 	log.WithFields(log.Fields{
 		"sourceLanguage": source,
 		"targetLanguage": target,
@@ -61,9 +64,16 @@ func DeepLTranslate(txt []string, source, target, apiKey string) ([]string, erro
 	params.Add("target_lang", target)
 	reqBody := strings.NewReader(params.Encode())
 
-	resp, err := http.Post(baseUrl+"translate", "application/x-www-form-urlencoded", reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseUrl+"translate", reqBody)
+	if err != nil {
+		log.Errorf("http.NewRequest: %v", err)
+		return TranslationError("Translation request failed.", txt), err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		log.Errorf("http.Post: %v", err)
+		log.Errorf("http.Do: %v", err)
 		return TranslationError("Translation request failed, ensure that your internet connection is stable.", txt), err
 	}
 	defer resp.Body.Close()
@@ -75,6 +85,13 @@ func DeepLTranslate(txt []string, source, target, apiKey string) ([]string, erro
 		return TranslationError("Translation request failed, ensure that your internet connection is stable and your API key is correct.", txt), err
 	}
 
+	// Rejections because of too many requests and server errors may not have a JSON body, and can be retried.
+	statusErr := &throttle.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	if throttle.Retryable(statusErr) {
+		log.Errorf("Translation request rejected: %v", resp.Status)
+		return TranslationError("Translation request failed, the DeepL API is busy or you sent too many requests.", txt), statusErr
+	}
+
 	// Empty response body, something went wrong.
 	if len(data) == 0 {
 		log.Error("Empty response body from translation request")
@@ -90,7 +107,7 @@ func DeepLTranslate(txt []string, source, target, apiKey string) ([]string, erro
 
 	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
 		log.Error("Non-200 status code")
-		return TranslationError("Translation request failed, ensure that your API key and source/target languages are correct.", txt), errors.New("non-200 status code")
+		return TranslationError("Translation request failed, ensure that your API key and source/target languages are correct.", txt), statusErr
 	}
 
 	var translated []string
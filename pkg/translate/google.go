@@ -6,11 +6,10 @@ import (
 	"cloud.google.com/go/translate"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/text/language"
-	"google.golang.org/api/option"
 )
 
 // GoogleTranslate translates the given slice of strings from source language to target language using the Google Cloud Translation API.
-func GoogleTranslate(txt []string, source, target, apiKey string) ([]string, error) {
+func GoogleTranslate(ctx context.Context, client *translate.Client, txt []string, source, target string) ([]string, error) {
 	log.WithFields(log.Fields{
 		"sourceLanguage": source,
 		"targetLanguage": target,
@@ -41,24 +40,6 @@ func GoogleTranslate(txt []string, source, target, apiKey string) ([]string, err
 		return TranslationError("Invalid target language selected in config.", txt), err
 	}
 
-	ctx := context.Background()
-	var client *translate.Client
-	if apiKey == "" {
-		client, err = translate.NewClient(ctx)
-		if err != nil {
-			log.Errorf("NewClient: %v", err)
-			return TranslationError("Translation request failed, ensure that the absolute path given for your Vision API service account key is correct", txt), err
-		}
-	} else {
-		apiKeyOption := option.WithAPIKey(apiKey)
-		client, err = translate.NewClient(ctx, apiKeyOption)
-		if err != nil {
-			log.Errorf("NewClient: %v", err)
-			return TranslationError("Translation request failed, ensure that your API key is correct.", txt), err
-		}
-	}
-	defer client.Close()
-
 	resp, err := client.Translate(ctx, txt, targetLang, &options)
 	log.Debug(resp)
 
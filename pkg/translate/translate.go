@@ -0,0 +1,107 @@
+package translate
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"cloud.google.com/go/translate"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/throttle"
+)
+
+// Client translates text with the service selected in the config. It is safe for concurrent use.
+type Client struct {
+	cfg   *config.File
+	limit *throttle.Limiter
+
+	mu     sync.Mutex
+	google *translate.Client // Created on first use.
+}
+
+// NewClient returns a client for the service selected in the given config. Requests are sent through the given limiter.
+func NewClient(cfg *config.File, limit *throttle.Limiter) *Client {
+	return &Client{cfg: cfg, limit: limit}
+}
+
+// Translate translates the given text with the service selected in the config. The request is aborted when ctx is cancelled.
+// On failure, the returned slice holds an error message for every element of txt.
+func (c *Client) Translate(ctx context.Context, txt []string) ([]string, error) {
+	log.Infof("Translating detected text with: %v", c.cfg.Translation.SelectedService)
+
+	var translated []string
+	err := c.limit.Do(ctx, throttle.Translation, 1, func() error {
+		var err error
+		translated, err = c.translate(ctx, txt)
+		return err
+	})
+	return translated, err
+}
+
+func (c *Client) translate(ctx context.Context, txt []string) ([]string, error) {
+	cfg := c.cfg
+	switch cfg.Translation.SelectedService {
+	case "google":
+		client, err := c.googleClient()
+		if err != nil {
+			if cfg.Translation.Google.APIKey == "" {
+				return TranslationError("Translation request failed, ensure that the absolute path given for your Vision API service account key is correct", txt), err
+			}
+			return TranslationError("Translation request failed, ensure that your API key is correct.", txt), err
+		}
+		return GoogleTranslate(
+			ctx,
+			client,
+			txt,
+			cfg.Translation.SourceLanguage,
+			cfg.Translation.TargetLanguage,
+		)
+	case "deepL":
+		return DeepLTranslate(
+			ctx,
+			txt,
+			cfg.Translation.SourceLanguage,
+			cfg.Translation.TargetLanguage,
+			cfg.Translation.DeepL.APIKey,
+		)
+	default:
+		return TranslationError(`Your config does not have a valid selected service, run the "manga-translator-setup" application again.`, txt),
+			errors.New("no selected service")
+	}
+}
+
+func (c *Client) googleClient() (*translate.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.google != nil {
+		return c.google, nil
+	}
+
+	// Without an API key, the service account key of the Vision API is used.
+	var opts []option.ClientOption
+	if apiKey := c.cfg.Translation.Google.APIKey; apiKey != "" {
+		opts = append(opts, option.WithAPIKey(apiKey))
+	}
+	client, err := translate.NewClient(context.Background(), opts...)
+	if err != nil {
+		log.Errorf("NewClient: %v", err)
+		return nil, err
+	}
+	c.google = client
+	return client, nil
+}
+
+// Close closes the connection to the translation service.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.google == nil {
+		return nil
+	}
+	err := c.google.Close()
+	c.google = nil
+	return err
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package window
+
+import (
+	"gioui.org/io/event"
+)
+
+// openHint is the hint of the path input of the thumbnail sidebar. It tells that files cannot be dropped into the window,
+// as dropping files is only supported on Windows.
+const openHint = "Open file, folder or URL (drag and drop is Windows only)"
+
+// acceptDrops does nothing, as dropping files into the window is only supported on Windows.
+// Pages can still be opened with the path input of the thumbnail sidebar.
+func acceptDrops(e event.Event, dropped chan<- []string) {}
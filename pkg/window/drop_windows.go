@@ -0,0 +1,88 @@
+package window
+
+import (
+	"syscall"
+	"unsafe"
+
+	"gioui.org/app"
+	"gioui.org/io/event"
+	log "github.com/sirupsen/logrus"
+)
+
+// Gio does not support files dropped from other applications,
+// so the window procedure is replaced to handle WM_DROPFILES before passing all other messages on.
+
+const (
+	wmDropFiles = 0x0233
+	gwlpWndProc = ^uintptr(3) // -4
+)
+
+var (
+	shell32 = syscall.NewLazyDLL("shell32.dll")
+	user32  = syscall.NewLazyDLL("user32.dll")
+
+	procDragAcceptFiles   = shell32.NewProc("DragAcceptFiles")
+	procDragQueryFileW    = shell32.NewProc("DragQueryFileW")
+	procDragFinish        = shell32.NewProc("DragFinish")
+	procSetWindowLongPtrW = user32.NewProc("SetWindowLongPtrW")
+	procSetWindowLongW    = user32.NewProc("SetWindowLongW") // 32-bit Windows has no SetWindowLongPtrW.
+	procCallWindowProcW   = user32.NewProc("CallWindowProcW")
+)
+
+// openHint is the hint of the path input of the thumbnail sidebar.
+const openHint = "Open or drop a file, folder or URL"
+
+var (
+	dropHWND     uintptr
+	dropWndProc  uintptr // Window procedure of Gio.
+	droppedFiles chan<- []string
+)
+
+// acceptDrops starts accepting files dropped into the window once it has been created.
+// The paths of the dropped files are sent to the channel.
+func acceptDrops(e event.Event, dropped chan<- []string) {
+	v, ok := e.(app.ViewEvent)
+	if !ok || v.HWND == 0 || v.HWND == dropHWND {
+		return
+	}
+
+	setWindowLong := procSetWindowLongPtrW
+	if setWindowLong.Find() != nil {
+		setWindowLong = procSetWindowLongW
+	}
+
+	droppedFiles = dropped
+	prev, _, err := setWindowLong.Call(v.HWND, gwlpWndProc, syscall.NewCallback(dropWindowProc))
+	if prev == 0 {
+		log.Warningf("Unable to accept dropped files: %v", err)
+		return
+	}
+	dropHWND = v.HWND
+	dropWndProc = prev
+	procDragAcceptFiles.Call(v.HWND, 1)
+}
+
+func dropWindowProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	if msg == wmDropFiles {
+		files := queryDroppedFiles(wParam)
+		procDragFinish.Call(wParam)
+		log.Debugf("Dropped files: %v", files)
+		// The window procedure must not wait for the frame loop.
+		go func() { droppedFiles <- files }()
+		return 0
+	}
+	r, _, _ := procCallWindowProcW.Call(dropWndProc, hwnd, msg, wParam, lParam)
+	return r
+}
+
+func queryDroppedFiles(hDrop uintptr) []string {
+	count, _, _ := procDragQueryFileW.Call(hDrop, 0xFFFFFFFF, 0, 0)
+	files := make([]string, 0, count)
+	for i := uintptr(0); i < count; i++ {
+		n, _, _ := procDragQueryFileW.Call(hDrop, i, 0, 0)
+		buf := make([]uint16, n+1)
+		procDragQueryFileW.Call(hDrop, i, uintptr(unsafe.Pointer(&buf[0])), n+1)
+		files = append(files, syscall.UTF16ToString(buf))
+	}
+	return files
+}
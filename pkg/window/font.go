@@ -0,0 +1,129 @@
+package window
+
+import (
+	"io"
+	"io/ioutil"
+
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Text sizes of the panels in sp.
+const (
+	defaultTextSize = 16
+	minTextSize     = 8
+	maxTextSize     = 72
+	textSizeStep    = 2
+)
+
+// panelFont is the font of the original and translated text in the panel below the image.
+type panelFont struct {
+	font    text.Font
+	size    float32 // In sp.
+	spacing float32 // Line height as a multiple of the line height of the font.
+}
+
+// loadPanelFont adds the font file of the settings to the collection under the given typeface and returns the panel font using it.
+// The Noto typeface is used if no font is set or it cannot be loaded.
+func loadPanelFont(fonts []text.FontFace, cfg config.FontSettings, typeface text.Typeface) ([]text.FontFace, panelFont) {
+	f := panelFont{
+		font:    text.Font{Typeface: "Noto"},
+		size:    defaultTextSize,
+		spacing: 1,
+	}
+
+	if cfg.Size > 0 {
+		f.size = cfg.Size
+	}
+	if cfg.LineSpacing > 0 {
+		f.spacing = cfg.LineSpacing
+	} else if cfg.LineSpacing < 0 {
+		log.Warningf("Line spacing %v must be greater than 0", cfg.LineSpacing)
+	}
+
+	if cfg.Path == "" {
+		return fonts, f
+	}
+
+	data, err := ioutil.ReadFile(cfg.Path)
+	if err != nil {
+		log.Warningf("Unable to read font file: %v", err)
+		return fonts, f
+	}
+	fnt := text.Font{Typeface: typeface}
+	fonts, err = appendOTC(fonts, fnt, data)
+	if err != nil {
+		log.Warningf("Failed to parse font file %q: %v", cfg.Path, err)
+		return fonts, f
+	}
+	log.Debugf("Loaded %v font: %v", typeface, cfg.Path)
+	f.font = fnt
+	return fonts, f
+}
+
+// Resize changes the text size by the given number of steps, within the minimum and maximum size.
+func (f *panelFont) Resize(steps int) {
+	f.size += float32(steps * textSizeStep)
+	if f.size < minTextSize {
+		f.size = minTextSize
+	} else if f.size > maxTextSize {
+		f.size = maxTextSize
+	}
+}
+
+// label returns a label with the panel font.
+func (f panelFont) label(th *material.Theme, txt string) material.LabelStyle {
+	l := material.Label(f.theme(th), unit.Sp(f.size), txt)
+	l.Font = f.font
+	return l
+}
+
+// editor returns an editor with the panel font.
+func (f panelFont) editor(th *material.Theme, editor *widget.Editor, hint string) material.EditorStyle {
+	e := material.Editor(f.theme(th), editor, hint)
+	e.Font = f.font
+	e.TextSize = unit.Sp(f.size)
+	return e
+}
+
+// theme returns a copy of the theme which lays out text with the line spacing of the font.
+func (f panelFont) theme(th *material.Theme) *material.Theme {
+	if f.spacing == 1 {
+		return th
+	}
+	spaced := *th
+	spaced.Shaper = spacedShaper{Shaper: th.Shaper, spacing: f.spacing}
+	return &spaced
+}
+
+// spacedShaper adds space below every line of the text laid out by the wrapped shaper.
+type spacedShaper struct {
+	text.Shaper
+	spacing float32
+}
+
+func (s spacedShaper) Layout(font text.Font, size fixed.Int26_6, maxWidth int, txt io.Reader) ([]text.Line, error) {
+	lines, err := s.Shaper.Layout(font, size, maxWidth, txt)
+	return s.space(lines), err
+}
+
+func (s spacedShaper) LayoutString(font text.Font, size fixed.Int26_6, maxWidth int, str string) []text.Line {
+	return s.space(s.Shaper.LayoutString(font, size, maxWidth, str))
+}
+
+func (s spacedShaper) space(lines []text.Line) []text.Line {
+	// The lines may be cached by the wrapped shaper, so they are copied before they are changed.
+	spaced := make([]text.Line, len(lines))
+	copy(spaced, lines)
+	for i := range spaced {
+		height := spaced[i].Ascent + spaced[i].Descent
+		spaced[i].Descent += fixed.Int26_6(float32(height) * (s.spacing - 1))
+	}
+	return spaced
+}
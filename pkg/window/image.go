@@ -1,6 +1,7 @@
 package window
 
 import (
+	"context"
 	"errors"
 	"image"
 	"math"
@@ -13,11 +14,11 @@ import (
 	"gioui.org/op/paint"
 	"gioui.org/unit"
 	"gioui.org/widget"
+	"gioui.org/widget/material"
 	"github.com/Drack112/Anime-OCR-Translator/pkg/cache"
-	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
 	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
 	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
-	"github.com/Drack112/Anime-OCR-Translator/pkg/translate"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/pipeline"
 	"github.com/labstack/gommon/log"
 )
 
@@ -26,90 +27,97 @@ type textBlocks struct {
 	loading  bool   // Is true if the process is in progress.
 	finished bool   // Is true the process is complete.
 	ok       bool   // Is true the process did not encounter any errors.
+
+	editing bool   // Is true while a manual edit of a block is being processed.
+	editErr string // Error of the last manual edit, empty if it succeeded.
 }
 
-func (t *textBlocks) getText(w *app.Window, cfg *config.File, img imageW.TranslatorImage, blocks *[]detect.TextBlock, blockButtons *[]widget.Clickable) {
-	t.loading = true
+// setText shows the detected and translated text of the page, or the error which prevented it.
+// If ctx is cancelled, the page is left unloaded so that it can be loaded again.
+func (t *textBlocks) setText(ctx context.Context, w *app.Window, img imageW.TranslatorImage, res pipeline.Result, err error, blocks *[]detect.TextBlock, blockButtons *[]widget.Clickable) {
+	canceled := false
 
 	defer func() {
 		t.loading = false
-		t.finished = true
+		t.finished = !canceled
 		t.ok = t.status == `Done!`
 		w.Invalidate()
 	}()
 
-	var blankCfg config.File
-
-	if *cfg == blankCfg {
+	if ctx.Err() != nil {
+		log.Debugf("Stopped loading page: %v", img.Hash)
+		canceled = true
+		t.status = ""
+		return
+	}
+	if errors.Is(err, pipeline.ErrBlankConfig) {
 		t.status = `Your config is either blank or doesn't exist, run the "manga-translator-setup" application to create one.`
 		return
 	}
-	var translateOnly bool
-
-	*blocks, translateOnly = cache.Check(img.Hash, cfg.Translation.SelectedService)
+	if res.Blocks == nil {
+		res.Blocks = []detect.TextBlock{}
+	}
+	// Blocks from the cache keep the colors of the theme they were detected with.
+	detect.Recolor(res.Blocks)
 
-	if *blocks == nil || translateOnly {
-		var err error
-		if !translateOnly {
-			t.status = `Detecting text...`
+	for range res.Blocks {
+		*blockButtons = append(*blockButtons, widget.Clickable{})
+	}
+	*blocks = res.Blocks
+	if err != nil {
+		t.status = err.Error()
+		// The blocks hold a more helpful message if the translation failed.
+		if len(res.Blocks) > 0 && !errors.As(err, new(*cache.Error)) {
+			t.status = res.Blocks[0].Translated
+		}
+		return
+	}
+	t.status = `Done!`
+}
 
-			annotation, err := detect.GetAnnotation(img.Image)
-			if err != nil {
-				*blocks = []detect.TextBlock{}
-				t.status = err.Error()
-				return
-			}
+// retranslated is the translation of the corrected text of a block, made in the background and set on the block by the frame loop.
+type retranslated struct {
+	page       *page
+	block      int
+	original   string
+	translated string
+	err        error
+}
 
-			*blocks = detect.OrganizeAnnotation(annotation)
-		}
+// retranslate translates the corrected original text of a block again.
+func retranslate(ctx context.Context, clients *pipeline.Clients, original string) (string, error) {
+	translated, err := clients.Translate.Translate(ctx, []string{original})
+	if err != nil {
+		return "", err
+	}
+	return translated[0], nil
+}
 
-		var allOriginal []string
-		for _, block := range *blocks {
-			*blockButtons = append(*blockButtons, widget.Clickable{})
-			allOriginal = append(allOriginal, block.Text)
-		}
+// addedRegion is the text of a region of a page, detected in the background and added as a new block by the frame loop.
+type addedRegion struct {
+	page  *page
+	block detect.TextBlock
+	err   error
+}
 
-		t.status = `Translating text...`
-		log.Infof("Translating detected text with: %v", cfg.Translation.SelectedService)
-
-		var allTranslated []string
-		if cfg.Translation.SelectedService == "google" {
-			allTranslated, err = translate.GoogleTranslate(
-				allOriginal,
-				cfg.Translation.SourceLanguage,
-				cfg.Translation.TargetLanguage,
-				cfg.Translation.Google.APIKey,
-			)
-		} else if cfg.Translation.SelectedService == "deepL" {
-			allTranslated, err = translate.DeepLTranslate(
-				allOriginal,
-				cfg.Translation.SourceLanguage,
-				cfg.Translation.TargetLanguage,
-				cfg.Translation.DeepL.APIKey,
-			)
-		} else {
-			t.status = `Your config does not have a valid selected service, run the "manga-translator-setup" application again.`
-			err = errors.New("no selected service")
-		}
-		for i, txt := range allTranslated {
-			(*blocks)[i].Translated = txt
-		}
-		if err == nil {
-			cache.Add(img.Hash, cfg.Translation.SelectedService, *blocks)
-		} else {
-			t.status = allTranslated[0]
-			return
-		}
-	} else {
+// detectRegion detects and translates the text inside the given area of the image, to become the block at index i.
+func detectRegion(ctx context.Context, clients *pipeline.Clients, img imageW.TranslatorImage, area image.Rectangle, i int) (detect.TextBlock, error) {
+	log.Debugf("Detecting text in region: %v", area)
+	annotation, err := clients.Detect.GetAnnotation(ctx, img.Crop(area))
+	if err != nil {
+		return detect.TextBlock{}, err
+	}
 
-		for range *blocks {
-			*blockButtons = append(*blockButtons, widget.Clickable{})
-		}
+	block := detect.MergeAnnotation(annotation, area, i)
+	translated, err := clients.Translate.Translate(ctx, []string{block.Text})
+	if err != nil {
+		return detect.TextBlock{}, err
 	}
-	t.status = `Done!`
+	block.Translated = translated[0]
+	return block, nil
 }
 
-func blockBox(img D, originalDims imageW.Dimensions, block detect.TextBlock, btn *widget.Clickable) layout.StackChild {
+func blockBox(img D, th *material.Theme, originalDims imageW.Dimensions, block detect.TextBlock, btn *widget.Clickable, overlay, selected bool) layout.StackChild {
 	return layout.Stacked(
 		func(gtx C) D {
 
@@ -135,19 +143,43 @@ func blockBox(img D, originalDims imageW.Dimensions, block detect.TextBlock, btn
 					},
 				}.Push(gtx.Ops)
 
+				opacity := float64(BlockOpacity)
+				if block.Hidden {
+					opacity /= 4
+				} else if selected {
+					opacity = math.Min(opacity*2, 1)
+				}
 				fillColor := block.Color
-				fillColor.A = 0x40
+				fillColor.A = uint8(opacity * 0xFF)
 				paint.ColorOp{Color: fillColor}.Add(gtx.Ops)
 				paint.PaintOp{}.Add(gtx.Ops)
 				defer area.Pop()
+
+				if overlay {
+					overlayText(gtx, th, block.Final())
+				}
+
+				// Mark blocks which have a human translation.
+				if block.Override != nil {
+					markerSize := gtx.Px(unit.Dp(8))
+					marker := gclip.Rect{Max: image.Pt(markerSize, markerSize)}.Push(gtx.Ops)
+					paint.ColorOp{Color: block.Color}.Add(gtx.Ops)
+					paint.PaintOp{}.Add(gtx.Ops)
+					marker.Pop()
+				}
 				return D{Size: gtx.Constraints.Max}
 			}
 
+			borderWidth := unit.Dp(2)
+			if selected {
+				borderWidth = unit.Dp(4)
+			}
+
 			borderedBox := func(gtx C) D {
 				return widget.Border{
 					Color:        block.Color,
 					CornerRadius: unit.Dp(1),
-					Width:        unit.Dp(2),
+					Width:        borderWidth,
 				}.Layout(gtx, box)
 			}
 
@@ -0,0 +1,261 @@
+package window
+
+import (
+	"errors"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// action is something the user can do with a keyboard shortcut.
+type action int
+
+const (
+	actionNextPage action = iota
+	actionPreviousPage
+	actionFirstPage
+	actionLastPage
+	actionNextBlock
+	actionPreviousBlock
+	actionCopyOriginal
+	actionCopyTranslated
+	actionToggleOverlay
+	actionShowHidden
+	actionSelectRegion
+	actionZoomIn
+	actionZoomOut
+	actionZoomReset
+	actionRetranslate
+	actionIncreaseTextSize
+	actionDecreaseTextSize
+	actionToggleThumbnails
+	actionCycleView
+	actionGoToPage
+	actionOpen
+	actionPaste
+	actionCapture
+	actionHelp
+	actionQuit
+)
+
+type keyAction struct {
+	action      action
+	description string                     // Shown in the help overlay.
+	keys        func(config.Keys) []string // Bindings from the config.
+	defaults    []string
+}
+
+var keyActions = []keyAction{
+	{actionNextPage, "Next page", func(k config.Keys) []string { return k.NextPage }, []string{"Right", "D"}},
+	{actionPreviousPage, "Previous page", func(k config.Keys) []string { return k.PreviousPage }, []string{"Left", "A"}},
+	{actionFirstPage, "First page", func(k config.Keys) []string { return k.FirstPage }, []string{"Home"}},
+	{actionLastPage, "Last page", func(k config.Keys) []string { return k.LastPage }, []string{"End"}},
+	{actionNextBlock, "Next block", func(k config.Keys) []string { return k.NextBlock }, []string{"Tab", "J"}},
+	{actionPreviousBlock, "Previous block", func(k config.Keys) []string { return k.PreviousBlock }, []string{"Shift+Tab", "K"}},
+	{actionCopyOriginal, "Copy original text", func(k config.Keys) []string { return k.CopyOriginal }, []string{"C"}},
+	{actionCopyTranslated, "Copy translated text", func(k config.Keys) []string { return k.CopyTranslated }, []string{"T"}},
+	{actionToggleOverlay, "Toggle translation overlay", func(k config.Keys) []string { return k.ToggleOverlay }, []string{"O"}},
+	{actionShowHidden, "Show hidden blocks", func(k config.Keys) []string { return k.ShowHidden }, []string{"H"}},
+	{actionSelectRegion, "Select a text region", func(k config.Keys) []string { return k.SelectRegion }, []string{"R"}},
+	{actionZoomIn, "Zoom in", func(k config.Keys) []string { return k.ZoomIn }, []string{"+", "="}},
+	{actionZoomOut, "Zoom out", func(k config.Keys) []string { return k.ZoomOut }, []string{"-"}},
+	{actionZoomReset, "Reset zoom", func(k config.Keys) []string { return k.ZoomReset }, []string{"0"}},
+	{actionRetranslate, "Retranslate selected block", func(k config.Keys) []string { return k.Retranslate }, []string{"Ctrl+R"}},
+	{actionIncreaseTextSize, "Increase text size", func(k config.Keys) []string { return k.IncreaseTextSize }, []string{"Ctrl++", "Ctrl+="}},
+	{actionDecreaseTextSize, "Decrease text size", func(k config.Keys) []string { return k.DecreaseTextSize }, []string{"Ctrl+-"}},
+	{actionToggleThumbnails, "Toggle page thumbnails", func(k config.Keys) []string { return k.ToggleThumbnails }, []string{"P"}},
+	{actionCycleView, "Switch view mode", func(k config.Keys) []string { return k.CycleView }, []string{"V"}},
+	{actionGoToPage, "Go to page", func(k config.Keys) []string { return k.GoToPage }, []string{"Ctrl+G"}},
+	{actionOpen, "Open file, folder or URL", func(k config.Keys) []string { return k.Open }, []string{"Ctrl+O"}},
+	{actionPaste, "Add image from clipboard", func(k config.Keys) []string { return k.Paste }, []string{"Ctrl+V"}},
+	{actionCapture, "Capture a screen region", func(k config.Keys) []string { return k.Capture }, []string{"Ctrl+Shift+S"}},
+	{actionHelp, "Show shortcuts", func(k config.Keys) []string { return k.Help }, []string{"?", "Shift+/", "F1"}},
+	{actionQuit, "Quit", func(k config.Keys) []string { return k.Quit }, []string{"Ctrl+Q"}},
+}
+
+// keyAliases maps readable key names to the names used by key.Event.
+var keyAliases = map[string]string{
+	"left":      key.NameLeftArrow,
+	"right":     key.NameRightArrow,
+	"up":        key.NameUpArrow,
+	"down":      key.NameDownArrow,
+	"return":    key.NameReturn,
+	"enter":     key.NameEnter,
+	"esc":       key.NameEscape,
+	"escape":    key.NameEscape,
+	"home":      key.NameHome,
+	"end":       key.NameEnd,
+	"backspace": key.NameDeleteBackward,
+	"delete":    key.NameDeleteForward,
+	"pageup":    key.NamePageUp,
+	"pagedown":  key.NamePageDown,
+	"tab":       key.NameTab,
+	"space":     key.NameSpace,
+}
+
+var modifierNames = map[string]key.Modifiers{
+	"ctrl":     key.ModCtrl,
+	"shift":    key.ModShift,
+	"alt":      key.ModAlt,
+	"cmd":      key.ModCommand,
+	"command":  key.ModCommand,
+	"super":    key.ModSuper,
+	"shortcut": key.ModShortcut,
+}
+
+type binding struct {
+	name string
+	mods key.Modifiers
+}
+
+// keymap maps key presses to actions.
+type keymap struct {
+	bindings map[binding]action
+	help     [][2]string // Description and bindings of every action.
+}
+
+func newKeymap(keys config.Keys) keymap {
+	k := keymap{bindings: make(map[binding]action)}
+	for _, a := range keyActions {
+		bindings := a.keys(keys)
+		if len(bindings) == 0 {
+			bindings = a.defaults
+		}
+
+		var valid []string
+		for _, s := range bindings {
+			b, err := parseBinding(s)
+			if err != nil {
+				log.Warningf("Invalid key binding %q: %v", s, err)
+				continue
+			}
+			k.bindings[b] = a.action
+			valid = append(valid, s)
+		}
+		k.help = append(k.help, [2]string{a.description, strings.Join(valid, ", ")})
+	}
+	return k
+}
+
+// parseBinding parses a key name with optional modifiers, such as "Ctrl+Shift+C" or "Ctrl++".
+func parseBinding(s string) (binding, error) {
+	var b binding
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return b, errors.New("empty binding")
+	}
+
+	var mods []string
+	if strings.HasSuffix(s, "+") {
+		b.name = "+"
+		mods = strings.Split(strings.TrimSuffix(s[:len(s)-1], "+"), "+")
+	} else {
+		parts := strings.Split(s, "+")
+		b.name = parts[len(parts)-1]
+		mods = parts[:len(parts)-1]
+	}
+
+	for _, m := range mods {
+		if m == "" {
+			continue
+		}
+		mod, ok := modifierNames[strings.ToLower(m)]
+		if !ok {
+			return b, errors.New("unknown modifier " + m)
+		}
+		b.mods |= mod
+	}
+
+	if alias, ok := keyAliases[strings.ToLower(b.name)]; ok {
+		b.name = alias
+	} else if utf8.RuneCountInString(b.name) == 1 {
+		// Letter keys are reported in upper case.
+		b.name = strings.ToUpper(b.name)
+	}
+	return b, nil
+}
+
+// editorKeys are used by the text editors together with Ctrl, so they do not trigger shortcuts while typing.
+var editorKeys = map[string]bool{
+	"A": true, "C": true, "V": true, "X": true, "Z": true, "Y": true,
+	key.NameLeftArrow: true, key.NameRightArrow: true, key.NameUpArrow: true, key.NameDownArrow: true,
+	key.NameHome: true, key.NameEnd: true, key.NamePageUp: true, key.NamePageDown: true,
+	key.NameDeleteBackward: true, key.NameDeleteForward: true,
+}
+
+// typing returns true if the key is used by a focused text editor instead of triggering a shortcut.
+// These are keys without Ctrl, Alt or Command, and the editing shortcuts of the editor.
+func typing(e key.Event) bool {
+	if e.Modifiers&(key.ModCtrl|key.ModAlt|key.ModCommand) == 0 {
+		return true
+	}
+	return editorKeys[e.Name]
+}
+
+// lookup returns the action bound to the given key press.
+// Bindings without Shift also match if Shift is held, unless Shift is bound separately.
+func (k keymap) lookup(e key.Event) (action, bool) {
+	if a, ok := k.bindings[binding{e.Name, e.Modifiers}]; ok {
+		return a, true
+	}
+	a, ok := k.bindings[binding{e.Name, e.Modifiers &^ key.ModShift}]
+	return a, ok
+}
+
+// helpWidget lists all actions with their bindings.
+func (k keymap) helpWidget(gtx C, th *material.Theme) D {
+	return layout.Center.Layout(gtx, func(gtx C) D {
+		return widget.Border{
+			Color:        DividerColor,
+			CornerRadius: unit.Dp(2),
+			Width:        unit.Dp(2),
+		}.Layout(gtx, func(gtx C) D {
+			return layout.Stack{}.Layout(gtx,
+				layout.Expanded(func(gtx C) D {
+					return colorBox(gtx, gtx.Constraints.Min, BackgroundColor)
+				}),
+				layout.Stacked(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx C) D {
+						rows := []layout.FlexChild{
+							layout.Rigid(func(gtx C) D {
+								return panelTitle(gtx, th, "Shortcuts")
+							}),
+						}
+						for _, line := range k.help {
+							line := line
+							rows = append(rows, layout.Rigid(func(gtx C) D {
+								return layout.Flex{}.Layout(gtx,
+									layout.Rigid(func(gtx C) D {
+										gtx.Constraints.Min.X = gtx.Px(unit.Dp(220))
+										return helpLabel(gtx, th, line[0], TextColor)
+									}),
+									layout.Rigid(func(gtx C) D {
+										return helpLabel(gtx, th, line[1], DividerColor)
+									}),
+								)
+							}))
+						}
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx, rows...)
+					})
+				}),
+			)
+		})
+	})
+}
+
+func helpLabel(gtx C, th *material.Theme, txt string, col color.NRGBA) D {
+	l := material.Body1(th, txt)
+	l.Font = text.Font{Typeface: "Noto"}
+	l.Color = col
+	return l.Layout(gtx)
+}
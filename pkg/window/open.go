@@ -0,0 +1,88 @@
+package window
+
+import (
+	"fmt"
+	"strings"
+
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	log "github.com/sirupsen/logrus"
+)
+
+// opened holds the images opened in the background, to be added as new pages.
+type opened struct {
+	images []imageW.TranslatorImage
+	errs   []string
+}
+
+// status describes the result of the opening for the thumbnail sidebar.
+func (o opened) status() string {
+	switch {
+	case len(o.errs) == 0:
+		return fmt.Sprintf("Opened %d page(s)", len(o.images))
+	case len(o.images) == 0:
+		return strings.Join(o.errs, "\n")
+	default:
+		return fmt.Sprintf("Opened %d page(s)\n%s", len(o.images), strings.Join(o.errs, "\n"))
+	}
+}
+
+// openPaths opens the images of the given files, folders and URLs and sends them to the channel.
+func openPaths(paths []string, result chan<- opened) {
+	var o opened
+	for _, path := range paths {
+		path = strings.Trim(strings.TrimSpace(path), `"`)
+		if path == "" {
+			continue
+		}
+
+		url := strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+		files := []string{path}
+		if !url {
+			var err error
+			files, err = imageW.Paths(path)
+			if err != nil {
+				log.Warningf("Unable to open %v: %v", path, err)
+				o.errs = append(o.errs, err.Error())
+				continue
+			}
+		}
+
+		for _, file := range files {
+			log.Debugf("Opening image: %v", file)
+			img, err := imageW.Load(file, url, false)
+			if err != nil {
+				log.Warningf("Unable to open %v: %v", file, err)
+				o.errs = append(o.errs, fmt.Sprintf("%v: %v", file, err))
+				continue
+			}
+			o.images = append(o.images, img)
+		}
+	}
+	result <- o
+}
+
+// openCapture lets the user capture a region of the screen and sends it to the channel.
+func openCapture(command string, result chan<- opened) {
+	var o opened
+	img, err := imageW.Capture(command)
+	if err != nil {
+		log.Warningf("Unable to capture screen region: %v", err)
+		o.errs = append(o.errs, err.Error())
+	} else {
+		o.images = append(o.images, img)
+	}
+	result <- o
+}
+
+// openClipboard opens the image in the clipboard and sends it to the channel.
+func openClipboard(result chan<- opened) {
+	var o opened
+	img, err := imageW.Load("", false, true)
+	if err != nil {
+		log.Warningf("Unable to paste image: %v", err)
+		o.errs = append(o.errs, err.Error())
+	} else {
+		o.images = append(o.images, img)
+	}
+	result <- o
+}
@@ -0,0 +1,59 @@
+package window
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget/material"
+)
+
+var (
+	OverlayBackground = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	OverlayText       = color.NRGBA{A: 0xFF}
+)
+
+// Text sizes in sp tried by fitLabel, from largest to smallest.
+const (
+	maxOverlaySize  = 32
+	minOverlaySize  = 6
+	overlaySizeStep = 2
+)
+
+// overlayText covers the block with an opaque backdrop and draws the given text on top of it,
+// so that the translation can be read in place of the original text.
+func overlayText(gtx C, th *material.Theme, txt string) D {
+	colorBox(gtx, gtx.Constraints.Max, OverlayBackground)
+	return layout.UniformInset(unit.Dp(2)).Layout(gtx, func(gtx C) D {
+		return fitLabel(gtx, th, txt, OverlayText)
+	})
+}
+
+// fitLabel lays out the text wrapped to the available width, with the largest size at which it fits into the available height.
+func fitLabel(gtx C, th *material.Theme, txt string, col color.NRGBA) D {
+	var (
+		l       material.LabelStyle
+		scratch op.Ops
+	)
+	for size := maxOverlaySize; size >= minOverlaySize; size -= overlaySizeStep {
+		l = material.Label(th, unit.Sp(float32(size)), txt)
+		l.Font = text.Font{Typeface: "Noto"}
+		l.Alignment = text.Middle
+		l.Color = col
+
+		// Measure the label without drawing it.
+		scratch.Reset()
+		measure := gtx
+		measure.Ops = &scratch
+		measure.Constraints.Min = image.Point{}
+		if l.Layout(measure).Size.Y <= gtx.Constraints.Max.Y {
+			break
+		}
+	}
+
+	gtx.Constraints.Min = gtx.Constraints.Max
+	return layout.Center.Layout(gtx, l.Layout)
+}
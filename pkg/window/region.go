@@ -0,0 +1,138 @@
+package window
+
+import (
+	"image"
+	"image/color"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/op"
+	gclip "gioui.org/op/clip"
+	"gioui.org/unit"
+	"gioui.org/widget"
+)
+
+// regionSelector lets the user draw a rectangle over the page image to add a text block by hand.
+type regionSelector struct {
+	// Active is true while the selection mode is enabled.
+	Active bool
+
+	drag     bool
+	dragID   pointer.ID
+	start    f32.Point
+	end      f32.Point
+	selected *image.Rectangle // Last selection in the coordinates of the original image.
+}
+
+var regionColor = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+
+// Layout handles the pointer input over an image of the given size and draws the rectangle being selected.
+// The ratio is the scale of the displayed image compared to the original image.
+func (r *regionSelector) Layout(gtx C, size image.Point, ratio float32) D {
+	if !r.Active {
+		r.drag = false
+		return D{}
+	}
+
+	r.handleInputs(gtx, size, ratio)
+
+	area := gclip.Rect{Max: size}.Push(gtx.Ops)
+	pointer.InputOp{Tag: r,
+		Types: pointer.Press | pointer.Drag | pointer.Release,
+		Grab:  r.drag,
+	}.Add(gtx.Ops)
+	pointer.CursorCrosshair.Add(gtx.Ops)
+	area.Pop()
+
+	if r.drag {
+		rect := r.rect()
+		defer op.Offset(layoutPt(rect.Min)).Push(gtx.Ops).Pop()
+		gtx.Constraints.Min = rect.Size()
+		gtx.Constraints.Max = rect.Size()
+
+		widget.Border{
+			Color: regionColor,
+			Width: unit.Dp(2),
+		}.Layout(gtx, func(gtx C) D {
+			fill := regionColor
+			fill.A = 0x40
+			return colorBox(gtx, gtx.Constraints.Max, fill)
+		})
+	}
+
+	return D{Size: size}
+}
+
+func (r *regionSelector) handleInputs(gtx C, size image.Point, ratio float32) {
+	for _, ev := range gtx.Events(r) {
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		pos := clampPt(e.Position, size)
+		switch e.Type {
+		case pointer.Press:
+			if r.drag {
+				break
+			}
+			r.drag = true
+			r.dragID = e.PointerID
+			r.start, r.end = pos, pos
+		case pointer.Drag:
+			if r.dragID != e.PointerID {
+				break
+			}
+			r.end = pos
+		case pointer.Release:
+			if !r.drag || r.dragID != e.PointerID {
+				break
+			}
+			r.end = pos
+			r.drag = false
+			rect := r.rect()
+			// Ignore accidental clicks which do not cover any area.
+			if rect.Dx() > 4 && rect.Dy() > 4 {
+				selected := image.Rect(
+					int(float32(rect.Min.X)/ratio), int(float32(rect.Min.Y)/ratio),
+					int(float32(rect.Max.X)/ratio), int(float32(rect.Max.Y)/ratio),
+				)
+				r.selected = &selected
+			}
+		case pointer.Cancel:
+			r.drag = false
+		}
+	}
+}
+
+// Selected returns the rectangle drawn by the user since the last call, in the coordinates of the original image.
+func (r *regionSelector) Selected() (image.Rectangle, bool) {
+	if r.selected == nil {
+		return image.Rectangle{}, false
+	}
+	rect := *r.selected
+	r.selected = nil
+	return rect, true
+}
+
+func (r *regionSelector) rect() image.Rectangle {
+	return image.Rect(int(r.start.X), int(r.start.Y), int(r.end.X), int(r.end.Y))
+}
+
+func clampPt(pt f32.Point, size image.Point) f32.Point {
+	if pt.X < 0 {
+		pt.X = 0
+	} else if pt.X > float32(size.X) {
+		pt.X = float32(size.X)
+	}
+	if pt.Y < 0 {
+		pt.Y = 0
+	} else if pt.Y > float32(size.Y) {
+		pt.Y = float32(size.Y)
+	}
+	return pt
+}
+
+func layoutPt(pt image.Point) f32.Point {
+	return f32.Pt(float32(pt.X), float32(pt.Y))
+}
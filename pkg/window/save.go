@@ -0,0 +1,63 @@
+package window
+
+import (
+	"sync"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/cache"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
+	log "github.com/sirupsen/logrus"
+)
+
+// blockSaver writes the blocks of edited pages to the cache in the background, one page at a time.
+// The writer is started by pageList.saveBlocks, so that the window waits for it before closing.
+// Only the latest blocks of a page are written, so quick edits cannot overwrite a newer state with an older one.
+type blockSaver struct {
+	mu      sync.Mutex
+	pending map[cacheKey][]detect.TextBlock // Blocks waiting to be written.
+	running bool                            // Is true while the writer goroutine is running.
+}
+
+type cacheKey struct {
+	hash    string
+	service string
+}
+
+// save queues a copy of the blocks to be written to the cache, replacing blocks of the same page which are still waiting.
+// It returns true if the writer is not running and has to be started with write.
+func (s *blockSaver) save(hash, service string, blocks []detect.TextBlock) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending == nil {
+		s.pending = make(map[cacheKey][]detect.TextBlock)
+	}
+	s.pending[cacheKey{hash, service}] = append([]detect.TextBlock(nil), blocks...)
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+// write writes the queued blocks until there are none left.
+func (s *blockSaver) write() {
+	for {
+		s.mu.Lock()
+		if len(s.pending) == 0 {
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+		var key cacheKey
+		var blocks []detect.TextBlock
+		for key, blocks = range s.pending {
+			break
+		}
+		delete(s.pending, key)
+		s.mu.Unlock()
+
+		if err := cache.Update(key.hash, key.service, blocks); err != nil {
+			log.Errorf("Unable to save blocks of %v: %v", key.hash, err)
+		}
+	}
+}
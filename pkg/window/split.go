@@ -52,7 +52,7 @@ func (s *VSplit) Layout(gtx C, top, bottom layout.Widget) D {
 
 		barRect := image.Rect(0, topSize, gtx.Constraints.Max.X, bottomOffset)
 		area := clip.Rect(barRect).Push(gtx.Ops)
-		paint.ColorOp{Color: LightGray}.Add(gtx.Ops)
+		paint.ColorOp{Color: TextColor}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
 		pointer.InputOp{Tag: s,
 			Types: pointer.Press | pointer.Drag | pointer.Release | pointer.Enter | pointer.Leave,
@@ -65,7 +65,7 @@ func (s *VSplit) Layout(gtx C, top, bottom layout.Widget) D {
 			lineY := topSize + ((bar / 4) * i)
 			gripper := image.Rect((gtx.Constraints.Max.X/2)-20, lineY+1, (gtx.Constraints.Max.X/2)+20, lineY-1)
 			gripArea := clip.Rect(gripper).Push(gtx.Ops)
-			paint.ColorOp{Color: DarkGray}.Add(gtx.Ops)
+			paint.ColorOp{Color: BackgroundColor}.Add(gtx.Ops)
 			paint.PaintOp{}.Add(gtx.Ops)
 			gripArea.Pop()
 		}
@@ -168,7 +168,7 @@ func (s *HSplit) Layout(gtx C, left, right layout.Widget) D {
 	{
 		barRect := image.Rect(leftSize, 0, rightOffset, gtx.Constraints.Max.Y)
 		area := clip.Rect{Max: barRect.Max, Min: barRect.Min}.Push(gtx.Ops)
-		paint.ColorOp{Color: Gray}.Add(gtx.Ops)
+		paint.ColorOp{Color: DividerColor}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
 		area.Pop()
 	}
@@ -0,0 +1,205 @@
+package window
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+)
+
+var (
+	thumbnailWidth = unit.Dp(120) // Width of the sidebar.
+	thumbnailSize  = float32(240) // Longest side of the thumbnail images in pixels.
+
+	statusDone  = color.NRGBA{R: 0x4C, G: 0xAF, B: 0x50, A: 0xFF}
+	statusError = color.NRGBA{R: 0xE5, G: 0x39, B: 0x35, A: 0xFF}
+)
+
+// thumbnailBar is a collapsible sidebar listing all pages with their loading status.
+type thumbnailBar struct {
+	// Visible is true while the sidebar is shown.
+	Visible bool
+
+	list    widget.List
+	buttons []widget.Clickable
+	goTo    widget.Editor
+	page    int // Page selected by the user, -1 if none was selected since the last call of Selected.
+
+	open  widget.Editor
+	paths []string // Paths submitted by the user since the last call of Opened.
+	// Status is the result of the last opening of pages.
+	Status string
+}
+
+func newThumbnailBar() *thumbnailBar {
+	t := &thumbnailBar{page: -1}
+	t.list.Axis = layout.Vertical
+	t.goTo.SingleLine = true
+	t.goTo.Submit = true
+	t.open.SingleLine = true
+	t.open.Submit = true
+	return t
+}
+
+// Selected returns the index of the page which the user clicked or typed into the "go to page" input since the last call.
+func (t *thumbnailBar) Selected() (int, bool) {
+	page := t.page
+	t.page = -1
+	return page, page >= 0
+}
+
+// Opened returns the files, folders or URLs which the user typed into the "open" input since the last call.
+// Several paths can be separated by "|".
+func (t *thumbnailBar) Opened() ([]string, bool) {
+	paths := t.paths
+	t.paths = nil
+	return paths, len(paths) > 0
+}
+
+// Focus opens the sidebar and moves the keyboard focus to the "go to page" input.
+func (t *thumbnailBar) Focus() {
+	t.Visible = true
+	t.goTo.Focus()
+}
+
+// FocusOpen opens the sidebar and moves the keyboard focus to the "open" input.
+func (t *thumbnailBar) FocusOpen() {
+	t.Visible = true
+	t.open.Focus()
+}
+
+// editing returns true if one of the inputs has keyboard focus.
+func (t *thumbnailBar) editing() bool {
+	return t.goTo.Focused() || t.open.Focused()
+}
+
+func (t *thumbnailBar) Layout(gtx C, th *material.Theme, p pageList) D {
+	for len(t.buttons) < p.len {
+		t.buttons = append(t.buttons, widget.Clickable{})
+	}
+	for i := range p.pages {
+		if t.buttons[i].Clicked() {
+			t.page = i
+		}
+	}
+	for _, e := range t.goTo.Events() {
+		if e, ok := e.(widget.SubmitEvent); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(e.Text))
+			if err == nil && n >= 1 && n <= p.len {
+				t.page = n - 1
+			}
+			t.goTo.SetText("")
+		}
+	}
+	for _, e := range t.open.Events() {
+		if e, ok := e.(widget.SubmitEvent); ok {
+			t.paths = append(t.paths, strings.Split(e.Text, "|")...)
+			t.open.SetText("")
+		}
+	}
+
+	gtx.Constraints.Min.X = gtx.Px(thumbnailWidth)
+	gtx.Constraints.Max.X = gtx.Constraints.Min.X
+
+	return layout.Stack{}.Layout(gtx,
+		layout.Expanded(func(gtx C) D {
+			return colorBox(gtx, gtx.Constraints.Max, BackgroundColor)
+		}),
+		layout.Stacked(func(gtx C) D {
+			gtx.Constraints.Min.Y = gtx.Constraints.Max.Y
+			return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+						e := material.Editor(th, &t.goTo, "Go to page")
+						e.Color = TextColor
+						e.HintColor = DividerColor
+						return e.Layout(gtx)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+						e := material.Editor(th, &t.open, openHint)
+						e.Color = TextColor
+						e.HintColor = DividerColor
+						return e.Layout(gtx)
+					})
+				}),
+				layout.Rigid(func(gtx C) D {
+					if t.Status == "" {
+						return D{}
+					}
+					return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+						l := material.Caption(th, t.Status)
+						l.Color = DividerColor
+						return l.Layout(gtx)
+					})
+				}),
+				layout.Rigid(divider),
+				layout.Flexed(1, func(gtx C) D {
+					return material.List(th, &t.list).Layout(gtx, p.len, func(gtx C, i int) D {
+						return t.thumbnail(gtx, th, p.pages[i], i, i == p.idx)
+					})
+				}),
+			)
+		}),
+	)
+}
+
+func (t *thumbnailBar) thumbnail(gtx C, th *material.Theme, pg *page, i int, current bool) D {
+	if !pg.thumbOK {
+		pg.thumb = paint.NewImageOp(pg.image.Thumbnail(thumbnailSize))
+		pg.thumbOK = true
+	}
+
+	status, statusColor := pageStatus(pg.text)
+
+	return Clickable(gtx, &t.buttons[i], true, func(gtx C) D {
+		return layout.UniformInset(unit.Dp(4)).Layout(gtx, func(gtx C) D {
+			return layout.Flex{Axis: layout.Vertical, Alignment: layout.Middle}.Layout(gtx,
+				layout.Rigid(func(gtx C) D {
+					img := func(gtx C) D {
+						return widget.Image{
+							Src:      pg.thumb,
+							Fit:      widget.ScaleDown,
+							Position: layout.Center,
+						}.Layout(gtx)
+					}
+					if !current {
+						return img(gtx)
+					}
+					return widget.Border{
+						Color: TextColor,
+						Width: unit.Dp(2),
+					}.Layout(gtx, img)
+				}),
+				layout.Rigid(func(gtx C) D {
+					l := material.Caption(th, fmt.Sprintf("%d · %s", i+1, status))
+					l.Alignment = text.Middle
+					l.Color = statusColor
+					return l.Layout(gtx)
+				}),
+			)
+		})
+	})
+}
+
+// pageStatus describes the loading status of a page.
+func pageStatus(txt textBlocks) (string, color.NRGBA) {
+	switch {
+	case txt.loading:
+		return "Loading", TextColor
+	case txt.finished && txt.ok:
+		return "Done", statusDone
+	case txt.finished:
+		return "Error", statusError
+	default:
+		return "Not loaded", DividerColor
+	}
+}
@@ -0,0 +1,86 @@
+package window
+
+import (
+	"image"
+
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
+)
+
+var (
+	tooltipWidth  = unit.Dp(300) // Maximum width, longer text is wrapped.
+	tooltipOffset = unit.Dp(16)
+)
+
+// tooltip draws the original and translated text of the block in a box next to the pointer position.
+// The box is moved to the other side of the pointer if it would not fit into the constraints.
+func tooltip(gtx C, th *material.Theme, pos f32.Point, block detect.TextBlock) D {
+	bounds := gtx.Constraints.Max
+
+	macro := op.Record(gtx.Ops)
+	content := func(gtx C) D {
+		gtx.Constraints.Min = image.Point{}
+		if width := gtx.Px(tooltipWidth); gtx.Constraints.Max.X > width {
+			gtx.Constraints.Max.X = width
+		}
+		return widget.Border{
+			Color:        DividerColor,
+			CornerRadius: unit.Dp(2),
+			Width:        unit.Dp(1),
+		}.Layout(gtx, func(gtx C) D {
+			return layout.Stack{}.Layout(gtx,
+				layout.Expanded(func(gtx C) D {
+					bg := BackgroundColor
+					bg.A = 0xE8
+					return colorBox(gtx, gtx.Constraints.Min, bg)
+				}),
+				layout.Stacked(func(gtx C) D {
+					return layout.UniformInset(unit.Dp(6)).Layout(gtx, func(gtx C) D {
+						return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+							layout.Rigid(tooltipLabel(th, block.Text)),
+							layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+							layout.Rigid(tooltipLabel(th, block.Final())),
+						)
+					})
+				}),
+			)
+		})
+	}
+	dims := content(gtx)
+	call := macro.Stop()
+
+	offset := float32(gtx.Px(tooltipOffset))
+	x, y := pos.X+offset, pos.Y+offset
+	if x+float32(dims.Size.X) > float32(bounds.X) {
+		x = pos.X - offset - float32(dims.Size.X)
+	}
+	if y+float32(dims.Size.Y) > float32(bounds.Y) {
+		y = pos.Y - offset - float32(dims.Size.Y)
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	defer op.Offset(f32.Pt(x, y)).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+	return dims
+}
+
+func tooltipLabel(th *material.Theme, txt string) layout.Widget {
+	return func(gtx C) D {
+		l := material.Body2(th, txt)
+		l.Font = text.Font{Typeface: "Noto"}
+		l.Color = TextColor
+		return l.Layout(gtx)
+	}
+}
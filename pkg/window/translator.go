@@ -1,6 +1,7 @@
 package window
 
 import (
+	"fmt"
 	"image"
 
 	"gioui.org/f32"
@@ -11,9 +12,89 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+
+	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
 )
 
-func translatorWidget(gtx C, th *material.Theme, btn *widget.Clickable, txt, title string) D {
+// translatorPanel holds the widgets of the text panel below the image.
+type translatorPanel struct {
+	originalBtn      widget.Clickable // Copies the loading status.
+	copyOriginalBtn  widget.Clickable
+	copyBtn          widget.Clickable
+	retranslateBtn   widget.Clickable
+	overrideBtn      widget.Clickable
+	clearOverrideBtn widget.Clickable
+	hideBtn          widget.Clickable
+	deleteBtn        widget.Clickable
+	moveUpBtn        widget.Clickable
+	moveDownBtn      widget.Clickable
+
+	original   widget.Editor
+	translated widget.Editor
+	shownT     string // Text last put into the translated editor.
+
+	originalFont   panelFont
+	translatedFont panelFont
+}
+
+// showTranslated puts the given text into the translated editor if it changed since the last call,
+// so that edits typed by the user are kept until the selected translation changes.
+func (t *translatorPanel) showTranslated(txt string) {
+	if txt != t.shownT {
+		t.translated.SetText(txt)
+		t.shownT = txt
+	}
+}
+
+// editing returns true if one of the text editors has keyboard focus.
+func (t *translatorPanel) editing() bool {
+	return t.original.Focused() || t.translated.Focused()
+}
+
+func (t *translatorPanel) Layout(gtx C, th *material.Theme, txt textBlocks, block *detect.TextBlock) D {
+	if !txt.finished {
+		return translatorWidget(gtx, th, t.originalFont, &t.originalBtn, txt.status, "Loading...")
+	} else if !txt.ok {
+		return translatorWidget(gtx, th, t.originalFont, &t.originalBtn, txt.status, "Error")
+	}
+
+	retranslateLabel := "Retranslate"
+	if txt.editing {
+		retranslateLabel = "Saving..."
+	}
+
+	hideLabel := "Hide"
+	if block != nil && block.Hidden {
+		hideLabel = "Unhide"
+	}
+
+	title, note := "Translated Text", ""
+	if block != nil && block.Override != nil {
+		title = "Manual Translation"
+		note = fmt.Sprintf("Edited by %s on %s", block.Override.Author, block.Override.Edited.Format("2006-01-02 15:04"))
+	}
+
+	var tlSplit HSplit
+
+	return tlSplit.Layout(gtx, func(gtx C) D {
+		return editorWidget(gtx, th, t.originalFont, &t.original, "Original Text", txt.editErr,
+			actionButton(th, &t.retranslateBtn, retranslateLabel),
+			actionButton(th, &t.hideBtn, hideLabel),
+			actionButton(th, &t.deleteBtn, "Delete"),
+			actionButton(th, &t.moveUpBtn, "↑"),
+			actionButton(th, &t.moveDownBtn, "↓"),
+			actionButton(th, &t.copyOriginalBtn, "Copy"),
+		)
+	}, func(gtx C) D {
+		return editorWidget(gtx, th, t.translatedFont, &t.translated, title, note,
+			actionButton(th, &t.overrideBtn, "Save Override"),
+			actionButton(th, &t.clearOverrideBtn, "Clear Override"),
+			actionButton(th, &t.copyBtn, "Copy"),
+		)
+	})
+}
+
+func translatorWidget(gtx C, th *material.Theme, font panelFont, btn *widget.Clickable, txt, title string) D {
 	return layout.Flex{
 		Axis:      layout.Vertical,
 		Spacing:   50,
@@ -21,14 +102,7 @@ func translatorWidget(gtx C, th *material.Theme, btn *widget.Clickable, txt, tit
 		layout.Rigid(divider),
 
 		layout.Rigid(func(gtx C) D {
-			return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
-				l := material.H4(th, title)
-				l.Font = text.Font{Typeface: "Noto"}
-				l.Alignment = text.Middle
-				l.Color = LightGray
-
-				return l.Layout(gtx)
-			})
+			return panelTitle(gtx, th, title)
 		}),
 		layout.Rigid(divider),
 
@@ -40,10 +114,9 @@ func translatorWidget(gtx C, th *material.Theme, btn *widget.Clickable, txt, tit
 					Left:  unit.Dp(10),
 					Right: unit.Dp(10)}.Layout(gtx, func(gtx C) D {
 
-					l := material.Body1(th, txt)
-					l.Font = text.Font{Typeface: "Noto"}
+					l := font.label(th, txt)
 					l.Alignment = text.Middle
-					l.Color = LightGray
+					l.Color = TextColor
 
 					return l.Layout(gtx)
 				})
@@ -54,6 +127,75 @@ func translatorWidget(gtx C, th *material.Theme, btn *widget.Clickable, txt, tit
 	)
 }
 
+// editorWidget is the editable variant of translatorWidget, with a row of action buttons below the text.
+// The note is displayed in small text under the title if it is not empty.
+func editorWidget(gtx C, th *material.Theme, font panelFont, editor *widget.Editor, title, note string, actions ...material.ButtonStyle) D {
+	var buttons []layout.FlexChild
+	for _, action := range actions {
+		action := action
+		buttons = append(buttons, layout.Rigid(func(gtx C) D {
+			return layout.UniformInset(unit.Dp(6)).Layout(gtx, action.Layout)
+		}))
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(divider),
+
+		layout.Rigid(func(gtx C) D {
+			return panelTitle(gtx, th, title)
+		}),
+		layout.Rigid(func(gtx C) D {
+			if note == "" {
+				return D{}
+			}
+			l := material.Caption(th, note)
+			l.Alignment = text.Middle
+			l.Color = DividerColor
+
+			return l.Layout(gtx)
+		}),
+		layout.Rigid(divider),
+
+		layout.Flexed(1, func(gtx C) D {
+			return layout.Inset{
+				Top:   unit.Dp(20),
+				Left:  unit.Dp(10),
+				Right: unit.Dp(10)}.Layout(gtx, func(gtx C) D {
+
+				gtx.Constraints.Min = gtx.Constraints.Max
+				e := font.editor(th, editor, "")
+				e.Color = TextColor
+				e.HintColor = DividerColor
+
+				return e.Layout(gtx)
+			})
+		}),
+
+		layout.Rigid(func(gtx C) D {
+			return layout.Flex{}.Layout(gtx, buttons...)
+		}),
+	)
+}
+
+// actionButton returns a button styled for the translator panel.
+func actionButton(th *material.Theme, btn *widget.Clickable, label string) material.ButtonStyle {
+	b := material.Button(th, btn, label)
+	b.Background = DividerColor
+	b.Color = TextColor
+	return b
+}
+
+func panelTitle(gtx C, th *material.Theme, title string) D {
+	return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx C) D {
+		l := material.H4(th, title)
+		l.Font = text.Font{Typeface: "Noto"}
+		l.Alignment = text.Middle
+		l.Color = TextColor
+
+		return l.Layout(gtx)
+	})
+}
+
 func divider(gtx C) D {
 	return layout.Center.Layout(gtx, func(gtx C) D {
 		maxHeight := unit.Dp(4)
@@ -62,7 +204,7 @@ func divider(gtx C) D {
 
 		height := float32(gtx.Px(maxHeight))
 		area := clip.UniformRRect(f32.Rectangle{Max: f32.Pt(float32(gtx.Constraints.Max.X), height)}, 0).Push(gtx.Ops)
-		paint.ColorOp{Color: Gray}.Add(gtx.Ops)
+		paint.ColorOp{Color: DividerColor}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
 		area.Pop()
 
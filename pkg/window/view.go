@@ -0,0 +1,151 @@
+package window
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/layout"
+	"gioui.org/op"
+	gclip "gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+
+	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+)
+
+// viewMode is the way pages are arranged in the viewer.
+type viewMode int
+
+const (
+	viewSingle  viewMode = iota // One page at a time.
+	viewSpread                  // Two pages side by side, read from right to left.
+	viewWebtoon                 // All pages below each other in a continuous vertical scroll.
+)
+
+var viewNames = []string{"single", "spread", "webtoon"}
+
+func (m viewMode) String() string {
+	return viewNames[m]
+}
+
+// next returns the view mode after m, wrapping around at the end.
+func (m viewMode) next() viewMode {
+	return (m + 1) % viewMode(len(viewNames))
+}
+
+// parseViewMode returns the view mode with the given name, the single page view if it is unknown.
+func parseViewMode(name string) viewMode {
+	for i, n := range viewNames {
+		if n == name {
+			return viewMode(i)
+		}
+	}
+	return viewSingle
+}
+
+// spreadStart returns the index of the first page of the spread containing page idx.
+// Pages are paired starting from the first one.
+func spreadStart(idx int) int {
+	return idx &^ 1
+}
+
+// visiblePages returns the indices of the pages shown in the given view mode.
+func visiblePages(p pageList, mode viewMode, scroll *widget.List) []int {
+	switch mode {
+	case viewSpread:
+		start := spreadStart(p.idx)
+		if start+1 < p.len {
+			return []int{start, start + 1}
+		}
+		return []int{start}
+	case viewWebtoon:
+		var pages []int
+		for i := scroll.Position.First; i < scroll.Position.First+scroll.Position.Count && i < p.len; i++ {
+			pages = append(pages, i)
+		}
+		return pages
+	default:
+		return []int{p.idx}
+	}
+}
+
+// maxStripHeight is the height of the strips a page image is split into for drawing.
+// Textures larger than this may not be supported by the GPU, and webtoon pages can be several times as tall.
+const maxStripHeight = 4096
+
+type imageStrip struct {
+	src  paint.ImageOp
+	rect image.Rectangle // Area of the strip in the page image.
+}
+
+// imageStrips splits the image into horizontal strips of at most maxStripHeight pixels.
+func imageStrips(img *image.RGBA) []imageStrip {
+	var strips []imageStrip
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y += maxStripHeight {
+		rect := image.Rect(b.Min.X, y, b.Max.X, y+maxStripHeight).Intersect(b)
+		strips = append(strips, imageStrip{
+			src:  paint.NewImageOp(img.SubImage(rect)),
+			rect: rect,
+		})
+	}
+	return strips
+}
+
+// pageImage draws the image of the page scaled to the given size.
+func pageImage(gtx C, pg *page, size image.Point) D {
+	if pg.strips == nil {
+		pg.strips = imageStrips(pg.image.Image)
+	}
+
+	scale := float32(size.X) / float32(pg.image.Dimensions.Width)
+	for _, s := range pg.strips {
+		top := int(float32(s.rect.Min.Y) * scale)
+		bottom := int(float32(s.rect.Max.Y) * scale)
+
+		stack := op.Offset(layoutPt(image.Pt(0, top))).Push(gtx.Ops)
+		gtx := gtx
+		gtx.Constraints = layout.Exact(image.Pt(size.X, bottom-top))
+		widget.Image{Src: s.src, Fit: widget.Fill}.Layout(gtx)
+		stack.Pop()
+	}
+	return D{Size: size}
+}
+
+// pageWidget draws a page with its text blocks. The page image is scaled to fit the constraints,
+// or only to fit their width if fitWidth is true.
+// The region selector is only laid out if it is not nil, so it should only be given for the current page.
+func pageWidget(gtx C, th *material.Theme, pg *page, sel *regionSelector, selected int, showHidden, overlay, fitWidth bool) D {
+	dims := pg.image.Dimensions
+	scale := float32(gtx.Constraints.Max.X) / float32(dims.Width)
+	if !fitWidth {
+		scale = float32(math.Min(float64(scale), float64(gtx.Constraints.Max.Y)/float64(dims.Height)))
+	}
+	size := image.Pt(int(float32(dims.Width)*scale), int(float32(dims.Height)*scale))
+
+	defer gclip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	imgWidget := pageImage(gtx, pg, size)
+
+	var blockWidgets []layout.StackChild
+
+	if pg.text.finished {
+		for i, block := range pg.blocks {
+			if block.Hidden && !showHidden {
+				continue
+			}
+			blockWidgets = append(blockWidgets, blockBox(imgWidget, th, dims, block, &pg.blockButtons[i], overlay, i == selected))
+		}
+	}
+
+	layout.Stack{}.Layout(gtx, blockWidgets...)
+
+	// The selector is laid out last so that it receives the pointer input before the blocks.
+	// Regions can also be added to pages where no text was found.
+	if sel != nil && pg.text.finished {
+		ratio := imageW.GetRatio(dims, float32(math.Max(float64(size.X), float64(size.Y))))
+		sel.Layout(gtx, size, ratio)
+	}
+
+	return imgWidget
+}
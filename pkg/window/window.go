@@ -1,11 +1,15 @@
 package window
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
+	"strings"
+	"sync"
 
 	"gioui.org/app"
+	"gioui.org/f32"
 	"gioui.org/font/gofont"
 	"gioui.org/font/opentype"
 	"gioui.org/io/key"
@@ -22,6 +26,8 @@ import (
 	"github.com/Drack112/Anime-OCR-Translator/pkg/config"
 	"github.com/Drack112/Anime-OCR-Translator/pkg/detect"
 	imageW "github.com/Drack112/Anime-OCR-Translator/pkg/image"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/pipeline"
+	"github.com/Drack112/Anime-OCR-Translator/pkg/session"
 	"github.com/gonoto/notosans"
 	log "github.com/sirupsen/logrus"
 )
@@ -31,42 +37,147 @@ type (
 	C = layout.Context
 )
 
+// Colors of the viewer, set from the theme in the config.
 var (
-	DarkGray  = color.NRGBA{R: 0x2B, G: 0x2B, B: 0x2B, A: 0xFF}
-	Gray      = color.NRGBA{R: 0x75, G: 0x75, B: 0x75, A: 0xFF}
-	LightGray = color.NRGBA{R: 0xCF, G: 0xCF, B: 0xCF, A: 0xFF}
+	BackgroundColor = color.NRGBA{R: 0x2B, G: 0x2B, B: 0x2B, A: 0xFF}
+	DividerColor    = color.NRGBA{R: 0x75, G: 0x75, B: 0x75, A: 0xFF}
+	TextColor       = color.NRGBA{R: 0xCF, G: 0xCF, B: 0xCF, A: 0xFF}
+
+	BlockOpacity float32 = 0.25 // Opacity of the block fills.
+)
+
+// Previous names of the colors of the viewer, which follow the theme like the colors they alias.
+//
+// Deprecated: Use BackgroundColor, DividerColor and TextColor instead.
+var (
+	DarkGray  = BackgroundColor
+	Gray      = DividerColor
+	LightGray = TextColor
 )
 
 var preLoadPages = 2
 
-func DrawFrame(w *app.Window, images []imageW.TranslatorImage, cfg config.File) error {
+// DrawFrame runs the viewer in the given window until it is closed.
+// If a previous session is given, its current page and layout are restored.
+// Images received from clip are added as new pages and translated right away.
+func DrawFrame(w *app.Window, images []imageW.TranslatorImage, cfg config.File, resume *session.File, clip <-chan imageW.TranslatorImage) error {
 
 	var ops op.Ops
 
 	var split = VSplit{Ratio: 0.60}
 
-	var p pageList
+	// Background work is cancelled when the window is closed, and waited for so that the cache is not left half written.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var tasks sync.WaitGroup
+
+	var panel translatorPanel
+	panel.original.Alignment = text.Middle
+	panel.translated.Alignment = text.Middle
+
+	fonts, err := appendOTC(gofont.Collection(), text.Font{Typeface: "Noto"}, notosans.OTC())
+	if err != nil {
+		log.Fatalf("Failed to parse font collection: %v", err)
+	}
+	fonts, panel.originalFont = loadPanelFont(fonts, cfg.Font.OriginalSettings(), "Original")
+	fonts, panel.translatedFont = loadPanelFont(fonts, cfg.Font.TranslatedSettings(), "Translated")
+	th := material.NewTheme(fonts)
+	// The theme is applied before any page is loaded, as the colors of detected blocks are read by the loading goroutines.
+	applyTheme(cfg.Theme.Palette(), th)
+
+	clients := pipeline.NewClients(&cfg)
+	defer clients.Close()
+
+	p := pageList{
+		ctx:      ctx,
+		tasks:    &tasks,
+		clients:  clients,
+		saves:    &blockSaver{},
+		progress: make(chan loadProgress),
+		loaded:   make(chan loadedText),
+	}
 	p.add(images)
 
 	log.Debugf("Number of pages loaded: %d", p.len)
 
-	go p.pages[p.idx].load(w, &cfg)
-	p.preLoad(preLoadPages, w, &cfg)
+	mode := viewSingle
+	var scroll widget.List // Scroll position of the webtoon view.
+	scroll.Axis = layout.Vertical
 
-	var (
-		originalBtn   = new(widget.Clickable)
-		translatedBtn = new(widget.Clickable)
-	)
+	if resume != nil {
+		split.Ratio = resume.SplitRatio
+		if resume.Page >= 0 && resume.Page < p.len {
+			p.idx = resume.Page
+		}
+		mode = parseViewMode(resume.View)
+		scroll.Position.First = p.idx
+	}
 
-	fonts := gofont.Collection()
-	fonts = appendOTC(fonts, text.Font{Typeface: "Noto"}, notosans.OTC())
-	th := material.NewTheme(fonts)
+	// Size of the window in dp, updated on every frame.
+	var windowSize f32.Point
+
+	p.load(p.idx, w, &cfg)
+	p.preLoad(preLoadPages, w, &cfg)
+
+	var sel regionSelector
+	thumbs := newThumbnailBar()
+	var vp viewport
 
 	var (
 		selectedO string // Original text
 		selectedT string // Translated text
+		selected  = -1   // Index of the selected block, -1 if none is selected.
 	)
 
+	keys := newKeymap(cfg.Keys)
+	showHelp := false
+	clearFocus := false
+
+	// focusPage makes the given page the current page and clears the selection, without moving the view.
+	focusPage := func(idx int) {
+		if idx < 0 || idx >= p.len || idx == p.idx {
+			return
+		}
+		p.idx = idx
+		selectedO, selectedT, selected = "", "", -1
+		panel.original.SetText("")
+		// Pages which were jumped to may not have been preloaded.
+		p.load(p.idx, w, &cfg)
+		p.preLoad(preLoadPages, w, &cfg)
+		saveSession(p, split, windowSize, mode)
+	}
+
+	// setPage shows the given page and makes it the current page.
+	setPage := func(idx int) {
+		if idx < 0 || idx >= p.len || idx == p.idx {
+			return
+		}
+		vp.Reset()
+		if mode == viewWebtoon {
+			scroll.Position = layout.Position{First: idx}
+		}
+		focusPage(idx)
+	}
+
+	showHidden := false
+	overlay := false // Draw the translations on top of the blocks.
+
+	// Pages opened while the viewer is running are loaded in the background.
+	openResults := make(chan opened)
+	dropped := make(chan []string)
+	// Blocks of selected regions are added to their page by the frame loop, as it is the only one changing the blocks.
+	regions := make(chan addedRegion)
+	retranslations := make(chan retranslated)
+
+	// addPages appends the images as new pages and shows the first of them, which starts its translation.
+	addPages := func(images []imageW.TranslatorImage) {
+		first := p.len
+		p.add(images)
+		log.Debugf("Number of pages loaded: %d", p.len)
+		setPage(first)
+		w.Invalidate()
+	}
+
 	for {
 		select {
 		case e := <-w.Events():
@@ -74,16 +185,91 @@ func DrawFrame(w *app.Window, images []imageW.TranslatorImage, cfg config.File)
 
 			case system.FrameEvent:
 				gtx := layout.NewContext(&ops, e)
-
-				for i, b := range p.pages[p.idx].blocks {
-					if p.pages[p.idx].blockButtons[i].Clicked() {
-						log.Debugf("Clicked Block %d", i)
-						selectedO = b.Text
-						selectedT = b.Translated
+				windowSize = f32.Pt(float32(e.Size.X)/e.Metric.PxPerDp, float32(e.Size.Y)/e.Metric.PxPerDp)
+
+				// Blocks of every shown page can be clicked, which makes their page the current one.
+				for j := range p.pages {
+					for i, b := range p.pages[j].blocks {
+						if p.pages[j].blockButtons[i].Clicked() {
+							log.Debugf("Clicked Block %d of page %d", i, j)
+							focusPage(j)
+							selected = i
+							panel.original.SetText(b.Text)
+						}
 					}
 				}
 
-				if originalBtn.Clicked() {
+				// Only one edit of the page may run at a time.
+				pg := p.pages[p.idx]
+				if area, ok := sel.Selected(); ok && !pg.text.editing {
+					log.Debugf("Adding region %v", area)
+					pg.text.editing = true
+					pg.text.editErr = ""
+					n := len(pg.blocks)
+					p.run(func() {
+						block, err := detectRegion(ctx, p.clients, pg.image, area, n)
+						select {
+						case regions <- addedRegion{pg, block, err}:
+						case <-ctx.Done():
+						}
+					})
+				}
+				canEdit := selected >= 0 && !pg.text.editing
+				// The block is read now, as the selection may change before the retranslation returns.
+				i := selected
+				if panel.retranslateBtn.Clicked() && canEdit {
+					log.Debugf("Retranslating Block %d", selected)
+					pg.text.editing = true
+					pg.text.editErr = ""
+					original := panel.original.Text()
+					p.run(func() {
+						translated, err := retranslate(ctx, p.clients, original)
+						select {
+						case retranslations <- retranslated{pg, i, original, translated, err}:
+						case <-ctx.Done():
+						}
+					})
+				} else if panel.overrideBtn.Clicked() && canEdit {
+					log.Debugf("Saving override for Block %d", selected)
+					translated := panel.translated.Text()
+					pg.setOverride(selected, translated)
+					p.saveBlocks(pg, &cfg)
+				} else if panel.clearOverrideBtn.Clicked() && canEdit {
+					log.Debugf("Clearing override for Block %d", selected)
+					pg.setOverride(selected, "")
+					p.saveBlocks(pg, &cfg)
+				}
+
+				// Removing and reordering blocks does not need any requests, so it is done right away.
+				if panel.hideBtn.Clicked() && canEdit {
+					pg.blocks[selected].Hidden = !pg.blocks[selected].Hidden
+					p.saveBlocks(pg, &cfg)
+				} else if panel.deleteBtn.Clicked() && canEdit {
+					log.Debugf("Deleting Block %d", selected)
+					pg.deleteBlock(selected)
+					p.saveBlocks(pg, &cfg)
+					selectedO, selectedT, selected = "", "", -1
+					panel.original.SetText("")
+				} else if panel.moveUpBtn.Clicked() && canEdit && selected > 0 {
+					pg.moveBlock(selected, selected-1)
+					p.saveBlocks(pg, &cfg)
+					selected--
+				} else if panel.moveDownBtn.Clicked() && canEdit && selected < len(pg.blocks)-1 {
+					pg.moveBlock(selected, selected+1)
+					p.saveBlocks(pg, &cfg)
+					selected++
+				}
+
+				// Blocks can be changed by a retranslation or by the user.
+				var selectedBlock *detect.TextBlock
+				if selected >= 0 {
+					selectedBlock = &p.pages[p.idx].blocks[selected]
+					selectedO = selectedBlock.Text
+					selectedT = selectedBlock.Final()
+				}
+				panel.showTranslated(selectedT)
+
+				if panel.originalBtn.Clicked() {
 					if !p.pages[p.idx].text.finished || !p.pages[p.idx].text.ok {
 						// Loading or error status.
 						w.WriteClipboard(p.pages[p.idx].text.status)
@@ -91,53 +277,290 @@ func DrawFrame(w *app.Window, images []imageW.TranslatorImage, cfg config.File)
 						// Original text. Detection and translation completed and succeeded.
 						w.WriteClipboard(selectedO)
 					}
-				} else if translatedBtn.Clicked() {
+				} else if panel.copyOriginalBtn.Clicked() {
+					// The original text as shown, including corrections which were not retranslated yet.
+					w.WriteClipboard(panel.original.Text())
+				} else if panel.copyBtn.Clicked() {
 					w.WriteClipboard(selectedT)
 				}
 
 				// Background
 				layout.Center.Layout(gtx, func(gtx C) D {
-					return colorBox(gtx, gtx.Constraints.Max, DarkGray)
+					return colorBox(gtx, gtx.Constraints.Max, BackgroundColor)
 				})
 
 				// Application
 				split.Layout(gtx, func(gtx C) D {
-					return imageWidget(gtx, th, p)
+					if !thumbs.Visible {
+						return imageWidget(gtx, th, p, &vp, &scroll, &sel, selected, mode, showHidden, overlay)
+					}
+					return layout.Flex{}.Layout(gtx,
+						layout.Rigid(func(gtx C) D {
+							return thumbs.Layout(gtx, th, p)
+						}),
+						layout.Flexed(1, func(gtx C) D {
+							return imageWidget(gtx, th, p, &vp, &scroll, &sel, selected, mode, showHidden, overlay)
+						}),
+					)
 				}, func(gtx C) D {
-					return translatorPanelWidget(gtx, th, p.pages[p.idx].text, originalBtn, translatedBtn, p.pages[p.idx].text.status, selectedO, selectedT)
+					return panel.Layout(gtx, th, p.pages[p.idx].text, selectedBlock)
 				})
+
+				if idx, ok := thumbs.Selected(); ok {
+					setPage(idx)
+					w.Invalidate()
+				}
+				if paths, ok := thumbs.Opened(); ok {
+					thumbs.Status = "Opening..."
+					go openPaths(paths, openResults)
+				}
+
+				// The current page follows the scrolling of the webtoon view.
+				if visible := visiblePages(p, mode, &scroll); mode == viewWebtoon && len(visible) > 0 {
+					if p.idx < visible[0] || p.idx > visible[len(visible)-1] {
+						focusPage(visible[0])
+						w.Invalidate()
+					}
+				}
+
+				if showHelp {
+					keys.helpWidget(gtx, th)
+				}
+				if clearFocus {
+					key.FocusOp{}.Add(gtx.Ops)
+					clearFocus = false
+				}
 				e.Frame(gtx.Ops)
 
 			case key.Event:
-				if e.State == key.Press {
-					if (e.Name == "→" || e.Name == "D") && p.idx < p.len-1 {
-						p.idx++
-						selectedO, selectedT = "", ""
-						p.preLoad(preLoadPages, w, &cfg)
-						w.Invalidate()
-					} else if (e.Name == "←" || e.Name == "A") && p.idx > 0 {
-						p.idx--
-						selectedO, selectedT = "", ""
-						w.Invalidate()
+				// Keys typed into the text editors must not trigger shortcuts, but shortcuts with modifiers such as Ctrl+R still work.
+				if e.State != key.Press || (panel.editing() || thumbs.editing()) && typing(e) {
+					break
+				}
+				if e.Name == key.NameEscape && showHelp {
+					showHelp = false
+					w.Invalidate()
+					break
+				}
+				act, ok := keys.lookup(e)
+				if !ok {
+					break
+				}
+
+				switch act {
+				case actionNextPage:
+					if mode == viewSpread {
+						setPage(spreadStart(p.idx) + 2)
+					} else {
+						setPage(p.idx + 1)
+					}
+				case actionPreviousPage:
+					if mode == viewSpread {
+						setPage(spreadStart(p.idx) - 2)
+					} else {
+						setPage(p.idx - 1)
 					}
+				case actionFirstPage:
+					setPage(0)
+				case actionLastPage:
+					setPage(p.len - 1)
+				case actionNextBlock, actionPreviousBlock:
+					step := 1
+					if act == actionPreviousBlock {
+						step = -1
+					}
+					if next := p.pages[p.idx].nextBlock(selected, step, showHidden); next >= 0 && p.pages[p.idx].text.finished {
+						selected = next
+						panel.original.SetText(p.pages[p.idx].blocks[selected].Text)
+					}
+					// Tab also moves the keyboard focus, which would otherwise end up in one of the text editors.
+					clearFocus = true
+				case actionCopyOriginal:
+					w.WriteClipboard(selectedO)
+				case actionCopyTranslated:
+					w.WriteClipboard(selectedT)
+				case actionToggleOverlay:
+					overlay = !overlay
+				case actionShowHidden:
+					showHidden = !showHidden
+				case actionSelectRegion:
+					sel.Active = !sel.Active
+				case actionZoomIn:
+					vp.ZoomIn()
+				case actionZoomOut:
+					vp.ZoomOut()
+				case actionZoomReset:
+					vp.Reset()
+				case actionRetranslate:
+					panel.retranslateBtn.Click()
+				case actionCycleView:
+					mode = mode.next()
+					vp.Reset()
+					scroll.Position = layout.Position{First: p.idx}
+					log.Debugf("View mode: %v", mode)
+				case actionIncreaseTextSize:
+					panel.originalFont.Resize(1)
+					panel.translatedFont.Resize(1)
+				case actionDecreaseTextSize:
+					panel.originalFont.Resize(-1)
+					panel.translatedFont.Resize(-1)
+				case actionToggleThumbnails:
+					thumbs.Visible = !thumbs.Visible
+				case actionGoToPage:
+					thumbs.Focus()
+				case actionOpen:
+					thumbs.FocusOpen()
+				case actionPaste:
+					thumbs.Status = "Opening..."
+					go openClipboard(openResults)
+				case actionCapture:
+					thumbs.Status = "Capturing..."
+					go openCapture(cfg.Capture.Command, openResults)
+				case actionHelp:
+					showHelp = !showHelp
+				case actionQuit:
+					w.Perform(system.ActionClose)
 				}
+				w.Invalidate()
 
 			case system.DestroyEvent:
+				saveSession(p, split, windowSize, mode)
+				cancel()
+				tasks.Wait()
 				return e.Err
+
+			default:
+				acceptDrops(e, dropped)
 			}
+
+		case paths := <-dropped:
+			thumbs.Visible = true
+			thumbs.Status = "Opening..."
+			go openPaths(paths, openResults)
+			w.Invalidate()
+
+		case o := <-openResults:
+			addPages(o.images)
+			thumbs.Status = o.status()
+
+		case l := <-p.progress:
+			for _, pg := range l.pages {
+				if pg.text.loading {
+					pg.text.status = l.status
+				}
+			}
+			w.Invalidate()
+
+		case l := <-p.loaded:
+			p.setLoaded(w, &cfg, l)
+
+		case r := <-retranslations:
+			pg := r.page
+			pg.text.editing = false
+			// The blocks of the page cannot be moved or deleted while the edit runs, so the index is still the same.
+			if r.err != nil {
+				log.Warningf("Unable to retranslate block %d: %v", r.block, r.err)
+				pg.text.editErr = r.err.Error()
+			} else {
+				pg.blocks[r.block].Text = r.original
+				pg.blocks[r.block].Translated = r.translated
+				p.saveBlocks(pg, &cfg)
+			}
+			w.Invalidate()
+
+		case r := <-regions:
+			pg := r.page
+			pg.text.editing = false
+			if r.err != nil {
+				log.Warningf("Unable to add region: %v", r.err)
+				if pg.text.ok {
+					pg.text.editErr = r.err.Error()
+				} else {
+					pg.text.status = r.err.Error()
+				}
+			} else {
+				// Pages where no text was found show the new block like any other page.
+				// Pages whose translation failed keep their error, so that they are translated again.
+				if len(pg.blocks) == 0 {
+					pg.text.ok, pg.text.status = true, `Done!`
+				}
+				pg.blockButtons = append(pg.blockButtons, widget.Clickable{})
+				pg.blocks = append(pg.blocks, r.block)
+				p.saveBlocks(pg, &cfg)
+			}
+			w.Invalidate()
+
+		case img, ok := <-clip:
+			if !ok {
+				clip = nil
+				break
+			}
+			addPages([]imageW.TranslatorImage{img})
 		}
 	}
 }
 
+// saveSession records the opened pages and the state of the window, so that they can be restored with "-resume".
+func saveSession(p pageList, split VSplit, windowSize f32.Point, mode viewMode) {
+	s := session.File{
+		Page:       -1,
+		SplitRatio: split.Ratio,
+		Width:      windowSize.X,
+		Height:     windowSize.Y,
+		View:       mode.String(),
+	}
+	for i, pg := range p.pages {
+		// Images from the clipboard cannot be opened again.
+		if pg.image.Source == "" {
+			continue
+		}
+		if i <= p.idx {
+			s.Page = len(s.Pages)
+		}
+		s.Pages = append(s.Pages, session.Page{Source: pg.image.Source, URL: pg.image.FromURL})
+	}
+	if len(s.Pages) == 0 {
+		return
+	}
+	if s.Page < 0 {
+		s.Page = 0
+	}
+	session.Save(s)
+}
+
 type pageList struct {
-	pages []page
+	pages []*page
 	idx   int // Current page.
 	len   int
+
+	ctx   context.Context // Cancelled when the window is closed.
+	tasks *sync.WaitGroup // Background work on the pages.
+
+	clients *pipeline.Clients
+	saves   *blockSaver // Writes edited blocks to the cache.
+
+	// Progress and text of the pages loaded in the background, which are set on the pages by the frame loop.
+	progress chan loadProgress
+	loaded   chan loadedText
+}
+
+// loadProgress is the status of pages which are being loaded.
+type loadProgress struct {
+	pages  []*page
+	status string
+}
+
+// loadedText is the result of loading the text of a page.
+type loadedText struct {
+	page *page
+	ctx  context.Context
+	res  pipeline.Result
+	err  error
 }
 
 func (p *pageList) add(images []imageW.TranslatorImage) {
 	for _, img := range images {
-		newPage := page{
+		newPage := &page{
 			image: img,
 		}
 		p.pages = append(p.pages, newPage)
@@ -145,10 +568,122 @@ func (p *pageList) add(images []imageW.TranslatorImage) {
 	}
 }
 
+// preLoad loads the text of the pages after the current page, and stops loading pages which are no longer close to it.
 func (p *pageList) preLoad(num int, w *app.Window, cfg *config.File) {
+	for i, pg := range p.pages {
+		// The previous page is kept, as it may be the other half of a spread.
+		if i < p.idx-1 || i > p.idx+num {
+			pg.stop()
+		}
+	}
+	var next []int
 	for i := 1; i <= num && i+p.idx < p.len; i++ {
-		go p.pages[i+p.idx].load(w, cfg)
+		next = append(next, i+p.idx)
+	}
+	p.loadBatch(next, w, cfg)
+}
+
+// load detects and translates the text of the page at index i in the background, unless it is loading or loaded already.
+func (p *pageList) load(i int, w *app.Window, cfg *config.File) {
+	pg := p.pages[i]
+	if pg.text.loading || pg.text.finished {
+		return
+	}
+	ctx, cancel := context.WithCancel(p.ctx)
+	pg.cancel = cancel
+	pg.text.loading = true
+	p.run(func() {
+		defer cancel()
+		res, err := pipeline.Process(ctx, pg.image, pipeline.Options{
+			Config:   cfg,
+			Clients:  p.clients,
+			Progress: p.reporter([]*page{pg}),
+		})
+		p.finish(loadedText{pg, ctx, res, err})
+	})
+}
+
+// loadBatch loads the text of the pages at the given indices in the background like load,
+// but detects the text of all of them with as few requests as possible.
+// Stopping one of the pages only cancels the requests once all pages of the batch are stopped.
+func (p *pageList) loadBatch(indices []int, w *app.Window, cfg *config.File) {
+	var pages []*page
+	var images []imageW.TranslatorImage
+	for _, i := range indices {
+		pg := p.pages[i]
+		if pg.text.loading || pg.text.finished {
+			continue
+		}
+		pages = append(pages, pg)
+		images = append(images, pg.image)
+	}
+	if len(pages) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(p.ctx)
+	remaining := len(pages) // Pages of the batch which have not been stopped, only changed by the frame loop.
+	for _, pg := range pages {
+		var once sync.Once
+		pg.cancel = func() {
+			once.Do(func() {
+				if remaining--; remaining == 0 {
+					cancel()
+				}
+			})
+		}
+		pg.text.loading = true
 	}
+	p.run(func() {
+		defer cancel()
+		results := pipeline.ProcessBatch(ctx, images, pipeline.Options{
+			Config:   cfg,
+			Clients:  p.clients,
+			Progress: p.reporter(pages),
+		})
+		for _, pg := range pages {
+			res := results[pg.image.Hash]
+			p.finish(loadedText{pg, ctx, res.Result, res.Err})
+		}
+	})
+}
+
+// reporter returns a progress function which passes the status of the pages to the frame loop.
+func (p *pageList) reporter(pages []*page) func(status string) {
+	return func(status string) {
+		select {
+		case p.progress <- loadProgress{pages, status}:
+		case <-p.ctx.Done():
+		}
+	}
+}
+
+// finish passes the loaded text of a page to the frame loop, unless the window is closing.
+func (p *pageList) finish(l loadedText) {
+	select {
+	case p.loaded <- l:
+	case <-p.ctx.Done():
+	}
+}
+
+// setLoaded shows the loaded text on its page. Pages whose load was cancelled are loaded again if they are still needed.
+// It must be called by the frame loop.
+func (p *pageList) setLoaded(w *app.Window, cfg *config.File, l loadedText) {
+	pg := l.page
+	pg.text.setText(l.ctx, w, pg.image, l.res, l.err, &pg.blocks, &pg.blockButtons)
+	if !pg.text.finished {
+		p.load(p.idx, w, cfg)
+		p.preLoad(preLoadPages, w, cfg)
+	}
+}
+
+// run runs f in the background. The window waits for it before closing.
+func (p *pageList) run(f func()) {
+	p.tasks.Add(1)
+	go func() {
+		defer p.tasks.Done()
+		f()
+	}()
 }
 
 type page struct {
@@ -156,45 +691,155 @@ type page struct {
 	blocks       []detect.TextBlock
 	blockButtons []widget.Clickable
 	text         textBlocks
+	thumb        paint.ImageOp // Scaled down image for the thumbnail sidebar.
+	thumbOK      bool          // Is true once thumb has been created.
+	strips       []imageStrip  // Image split for drawing, created when the page is first shown.
+
+	cancel context.CancelFunc // Stops the loading of the text, nil if it was never started.
 }
 
-func (p *page) load(w *app.Window, cfg *config.File) {
-	if !p.text.loading && !p.text.finished {
-		p.text.getText(w, cfg, p.image, &p.blocks, &p.blockButtons)
+// stop cancels the loading of the text of the page if it is in progress.
+// The page stays loading until the cancelled load returns, so that it is never loaded twice at the same time.
+func (p *page) stop() {
+	if p.cancel != nil && p.text.loading {
+		p.cancel()
 	}
 }
 
-func imageWidget(gtx C, th *material.Theme, p pageList) D {
-	mainImg := layout.Center.Layout(gtx, func(gtx C) D {
-		imgWidget := widget.Image{
-			Fit:      widget.Contain,
-			Position: layout.Center,
-			Src:      paint.NewImageOp(p.pages[p.idx].image.Image),
-		}.Layout(gtx)
+// nextBlock returns the index of the next visible block after index i in the direction of step,
+// wrapping around at the ends. Blocks are visited in the order of the slice, which is the reading order.
+// It returns -1 if the page has no visible blocks.
+func (p *page) nextBlock(i, step int, showHidden bool) int {
+	n := len(p.blocks)
+	if i < 0 || i >= n {
+		if step > 0 {
+			i = -1
+		} else {
+			i = n
+		}
+	}
+	for k := 1; k <= n; k++ {
+		j := ((i+step*k)%n + n) % n
+		if !p.blocks[j].Hidden || showHidden {
+			return j
+		}
+	}
+	return -1
+}
 
-		var blockWidgets []layout.StackChild
+// deleteBlock removes the block at index i from the page.
+func (p *page) deleteBlock(i int) {
+	p.blocks = append(p.blocks[:i:i], p.blocks[i+1:]...)
+	p.blockButtons = append(p.blockButtons[:i:i], p.blockButtons[i+1:]...)
+}
 
-		if p.pages[p.idx].text.finished {
-			for i, block := range p.pages[p.idx].blocks {
-				blockWidgets = append(blockWidgets, blockBox(imgWidget, p.pages[p.idx].image.Dimensions, block, &p.pages[p.idx].blockButtons[i]))
-			}
+// moveBlock swaps the block at index i with the block at index j, changing the reading order.
+func (p *page) moveBlock(i, j int) {
+	p.blocks[i], p.blocks[j] = p.blocks[j], p.blocks[i]
+	p.blockButtons[i], p.blockButtons[j] = p.blockButtons[j], p.blockButtons[i]
+}
+
+// setOverride stores the given text as the human translation of the block at index i.
+// An empty text removes the override, so the machine translation is used again.
+func (p *page) setOverride(i int, txt string) {
+	if txt == "" {
+		p.blocks[i].Override = nil
+	} else {
+		p.blocks[i].Override = detect.NewOverride(txt)
+	}
+}
+
+// saveBlocks writes the current blocks of the page to the cache in the background.
+// Pages whose translation failed are not written, as their blocks hold the error instead of a translation.
+func (p *pageList) saveBlocks(pg *page, cfg *config.File) {
+	if !pg.text.ok {
+		return
+	}
+	if p.saves.save(pg.image.Hash, cfg.Translation.SelectedService, pg.blocks) {
+		p.run(p.saves.write)
+	}
+}
+
+func imageWidget(gtx C, th *material.Theme, p pageList, vp *viewport, scroll *widget.List, sel *regionSelector, selected int, mode viewMode, showHidden, overlay bool) D {
+	vp.Locked = sel.Active
+
+	// Only the current page has a selection.
+	showPage := func(gtx C, i int, fitWidth bool) D {
+		if i != p.idx {
+			return pageWidget(gtx, th, p.pages[i], nil, -1, showHidden, overlay, fitWidth)
 		}
+		return pageWidget(gtx, th, p.pages[i], sel, selected, showHidden, overlay, fitWidth)
+	}
 
-		layout.Stack{}.Layout(gtx, blockWidgets...)
+	var mainImg D
+	switch mode {
+	case viewWebtoon:
+		// Scrolling moves through the pages instead of zooming, so the viewport is not used.
+		mainImg = material.List(th, scroll).Layout(gtx, p.len, func(gtx C, i int) D {
+			return showPage(gtx, i, true)
+		})
+	case viewSpread:
+		mainImg = vp.Layout(gtx, func(gtx C) D {
+			// Manga is read from right to left, so the first page of the spread is on the right.
+			// Both pages are aligned to the middle of the window, like the pages of an open book.
+			pages := visiblePages(p, mode, scroll)
+			right := layout.Flexed(1, func(gtx C) D {
+				gtx.Constraints.Min = gtx.Constraints.Max
+				return layout.W.Layout(gtx, func(gtx C) D {
+					return showPage(gtx, pages[0], false)
+				})
+			})
+			if len(pages) == 1 {
+				// The last page of an odd number of pages has no partner.
+				return layout.Flex{}.Layout(gtx, layout.Flexed(1, func(gtx C) D { return D{Size: gtx.Constraints.Max} }), right)
+			}
+			left := layout.Flexed(1, func(gtx C) D {
+				gtx.Constraints.Min = gtx.Constraints.Max
+				return layout.E.Layout(gtx, func(gtx C) D {
+					return showPage(gtx, pages[1], false)
+				})
+			})
+			return layout.Flex{}.Layout(gtx, left, right)
+		})
+	default:
+		mainImg = vp.Layout(gtx, func(gtx C) D {
+			return layout.Center.Layout(gtx, func(gtx C) D {
+				return showPage(gtx, p.idx, false)
+			})
+		})
+	}
 
-		return imgWidget
-	},
-	)
+	// Show the text of the block under the pointer.
+	// The webtoon view does not track the pointer, as it is not laid out in the viewport.
+	if pos, ok := vp.Pointer(); ok && mode != viewWebtoon && !sel.Active {
+		tooltipPages(gtx, th, p, visiblePages(p, mode, scroll), pos, showHidden)
+	}
+
+	var pageLabel string
 	if p.len > 1 {
-		pageNum := fmt.Sprintf("%d/%d", p.idx+1, p.len)
+		pageLabel = fmt.Sprintf("%d/%d", p.idx+1, p.len)
+	}
+	if sel.Active {
+		pageLabel = strings.TrimSpace(pageLabel + " Select a text region")
+	}
+	if showHidden {
+		pageLabel = strings.TrimSpace(pageLabel + " Showing hidden blocks")
+	}
+	switch mode {
+	case viewSpread:
+		pageLabel = strings.TrimSpace(pageLabel + " Spread view")
+	case viewWebtoon:
+		pageLabel = strings.TrimSpace(pageLabel + " Webtoon view")
+	}
+	if pageLabel != "" {
 		return layout.NW.Layout(gtx, func(gtx C) D {
 			return layout.Inset{
 				Left: unit.Dp(4),
 				Top:  unit.Dp(4),
 			}.Layout(gtx, func(gtx C) D {
-				pageLabel := material.Label(th, unit.Dp(20), pageNum)
-				pageLabel.Color = LightGray
-				return pageLabel.Layout(gtx)
+				l := material.Label(th, unit.Dp(20), pageLabel)
+				l.Color = TextColor
+				return l.Layout(gtx)
 			})
 		})
 	} else {
@@ -202,22 +847,41 @@ func imageWidget(gtx C, th *material.Theme, p pageList) D {
 	}
 }
 
-func translatorPanelWidget(gtx C, th *material.Theme, txt textBlocks, originalBtn, translatedBtn *widget.Clickable, status, selectedO, selectedT string) D {
-	if !txt.finished {
-		return translatorWidget(gtx, th, originalBtn, status, "Loading...")
-	} else if !txt.ok {
-		return translatorWidget(gtx, th, originalBtn, status, "Error")
-	} else {
-		var tlSplit HSplit
-
-		return tlSplit.Layout(gtx, func(gtx C) D {
-			return translatorWidget(gtx, th, originalBtn, selectedO, "Original Text")
-		}, func(gtx C) D {
-			return translatorWidget(gtx, th, translatedBtn, selectedT, "Translated Text")
-		})
+// tooltipPages shows the text of the hovered block on any of the given pages.
+func tooltipPages(gtx C, th *material.Theme, p pageList, pages []int, pos f32.Point, showHidden bool) {
+	for _, j := range pages {
+		if !p.pages[j].text.finished {
+			continue
+		}
+		for i, block := range p.pages[j].blocks {
+			if block.Hidden && !showHidden {
+				continue
+			}
+			if p.pages[j].blockButtons[i].Hovered() {
+				tooltip(gtx, th, pos, block)
+				return
+			}
+		}
 	}
 }
 
+// applyTheme sets the colors of the viewer and of newly detected blocks.
+func applyTheme(p config.Palette, th *material.Theme) {
+	BackgroundColor = p.Background
+	TextColor = p.Text
+	DividerColor = p.Divider
+	OverlayBackground = p.OverlayBackground
+	OverlayText = p.OverlayText
+	BlockOpacity = p.BlockOpacity
+	DarkGray, Gray, LightGray = BackgroundColor, DividerColor, TextColor
+	detect.SetBlockColors(p.BlockColors)
+
+	th.Palette.Bg = p.Background
+	th.Palette.Fg = p.Text
+	th.Palette.ContrastBg = p.Divider
+	th.Palette.ContrastFg = p.Text
+}
+
 func colorBox(gtx C, size image.Point, color color.NRGBA) D {
 	area := gclip.Rect{Max: size}.Push(gtx.Ops)
 	paint.ColorOp{Color: color}.Add(gtx.Ops)
@@ -226,10 +890,11 @@ func colorBox(gtx C, size image.Point, color color.NRGBA) D {
 	return D{Size: size}
 }
 
-func appendOTC(collection []text.FontFace, fnt text.Font, otc []byte) []text.FontFace {
+// appendOTC adds the fonts of a TTF, OTF or OTC file to the collection under the given font.
+func appendOTC(collection []text.FontFace, fnt text.Font, otc []byte) ([]text.FontFace, error) {
 	face, err := opentype.ParseCollection(otc)
 	if err != nil {
-		log.Fatalf("Failed to parse font collection: %v", err)
+		return collection, err
 	}
-	return append(collection, text.FontFace{Font: fnt, Face: face})
+	return append(collection, text.FontFace{Font: fnt, Face: face}), nil
 }
@@ -0,0 +1,236 @@
+package window
+
+import (
+	"image"
+	"math"
+
+	"gioui.org/f32"
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	gclip "gioui.org/op/clip"
+)
+
+// viewport zooms and pans its content with the mouse wheel or by pinching, and by dragging.
+// Pinching works on touch screens, as Gio does not report trackpad pinch gestures; trackpads zoom by scrolling instead.
+// The content is centered and cannot be panned out of view.
+// Pointer input of the content is transformed along with it, so block boxes can still be clicked.
+type viewport struct {
+	zoom    float32 // 1 shows the whole content, 0 is treated as 1.
+	pan     f32.Point
+	content f32.Rectangle // Bounds of the content in the viewport before zooming.
+	// Locked disables panning, so that dragging can be used by the content.
+	Locked bool
+
+	drag   bool
+	dragID pointer.ID
+	last   f32.Point
+
+	hover   bool
+	pointer f32.Point // Last position of the pointer over the viewport.
+
+	touches map[pointer.ID]f32.Point // Fingers on a touch screen, two of them zoom by pinching.
+}
+
+const (
+	minZoom  = 1
+	maxZoom  = 8
+	zoomStep = 1.25
+)
+
+// Layout lays out the content zoomed and panned by the current transformation.
+func (v *viewport) Layout(gtx C, content layout.Widget) D {
+	size := gtx.Constraints.Max
+	center := f32.Pt(float32(size.X)/2, float32(size.Y)/2)
+
+	v.handleInputs(gtx, center)
+
+	defer gclip.Rect{Max: size}.Push(gtx.Ops).Pop()
+	pointer.InputOp{Tag: v,
+		Types:        pointer.Scroll | pointer.Press | pointer.Drag | pointer.Release | pointer.Move | pointer.Enter | pointer.Leave,
+		ScrollBounds: image.Rect(0, -math.MaxInt32, 0, math.MaxInt32),
+		Grab:         v.drag,
+	}.Add(gtx.Ops)
+	if v.drag {
+		pointer.CursorGrabbing.Add(gtx.Ops)
+	}
+
+	// The content is laid out at its own size, so that its bounds are known for limiting the panning.
+	cgtx := gtx
+	cgtx.Constraints.Min = image.Point{}
+	macro := op.Record(gtx.Ops)
+	dims := content(cgtx)
+	call := macro.Stop()
+	offset := layout.FPt(size.Sub(dims.Size).Div(2))
+	v.content = f32.Rectangle{Min: offset, Max: offset.Add(layout.FPt(dims.Size))}
+	v.clampPan(size, center)
+
+	defer op.Affine(v.transform(center)).Push(gtx.Ops).Pop()
+	defer op.Offset(offset).Push(gtx.Ops).Pop()
+	call.Add(gtx.Ops)
+
+	return D{Size: size}
+}
+
+func (v *viewport) handleInputs(gtx C, center f32.Point) {
+	for _, ev := range gtx.Events(v) {
+		e, ok := ev.(pointer.Event)
+		if !ok {
+			continue
+		}
+
+		v.pointer = e.Position
+
+		switch e.Type {
+		case pointer.Enter, pointer.Move:
+			v.hover = true
+		case pointer.Leave:
+			v.hover = false
+		case pointer.Scroll:
+			if e.Scroll.Y < 0 {
+				v.zoomAt(e.Position, center, v.scale()*zoomStep)
+			} else if e.Scroll.Y > 0 {
+				v.zoomAt(e.Position, center, v.scale()/zoomStep)
+			}
+		case pointer.Press:
+			if e.Source == pointer.Touch {
+				if v.touches == nil {
+					v.touches = make(map[pointer.ID]f32.Point)
+				}
+				v.touches[e.PointerID] = e.Position
+			}
+			if v.drag {
+				break
+			}
+			v.drag = true
+			v.dragID = e.PointerID
+			v.last = e.Position
+		case pointer.Drag:
+			if _, ok := v.touches[e.PointerID]; ok && len(v.touches) == 2 {
+				v.pinch(e.PointerID, e.Position, center)
+				break
+			}
+			if !v.drag || v.dragID != e.PointerID {
+				break
+			}
+			// Panning is only useful if the content is larger than the viewport.
+			if v.scale() > minZoom && !v.Locked {
+				v.pan = v.pan.Add(e.Position.Sub(v.last))
+			}
+			v.last = e.Position
+		case pointer.Release:
+			delete(v.touches, e.PointerID)
+			if len(v.touches) == 0 {
+				v.drag = false
+			} else if e.PointerID == v.dragID {
+				// The remaining finger keeps panning.
+				for id, pos := range v.touches {
+					v.dragID, v.last = id, pos
+				}
+			}
+		case pointer.Cancel:
+			v.touches = nil
+			v.drag = false
+		}
+	}
+}
+
+// pinch zooms and pans by the movement of the finger with the given ID to pos, relative to the other finger.
+func (v *viewport) pinch(id pointer.ID, pos, center f32.Point) {
+	prev := v.touches[id]
+	v.touches[id] = pos
+	var other f32.Point
+	for oid, p := range v.touches {
+		if oid != id {
+			other = p
+		}
+	}
+
+	prevDist := distance(prev, other)
+	if prevDist == 0 {
+		return
+	}
+	prevMid, mid := prev.Add(other).Mul(0.5), pos.Add(other).Mul(0.5)
+	if !v.Locked {
+		v.pan = v.pan.Add(mid.Sub(prevMid))
+	}
+	v.zoomAt(mid, center, v.scale()*distance(pos, other)/prevDist)
+}
+
+func distance(a, b f32.Point) float32 {
+	d := a.Sub(b)
+	return float32(math.Hypot(float64(d.X), float64(d.Y)))
+}
+
+// clampPan limits the panning, so that zoomed content larger than the viewport always covers it,
+// and smaller content stays inside of it.
+func (v *viewport) clampPan(size image.Point, center f32.Point) {
+	s := v.scale()
+	clamp := func(pan, min, max, size, center float32) float32 {
+		// Pans at which the scaled content touches the near and far edge of the viewport.
+		a := -center - s*(min-center)
+		b := size - center - s*(max-center)
+		if a > b {
+			a, b = b, a
+		}
+		if pan < a {
+			return a
+		} else if pan > b {
+			return b
+		}
+		return pan
+	}
+	v.pan.X = clamp(v.pan.X, v.content.Min.X, v.content.Max.X, float32(size.X), center.X)
+	v.pan.Y = clamp(v.pan.Y, v.content.Min.Y, v.content.Max.Y, float32(size.Y), center.Y)
+}
+
+// Pointer returns the last position of the pointer, if it is over the viewport.
+func (v *viewport) Pointer() (f32.Point, bool) {
+	return v.pointer, v.hover || v.drag
+}
+
+// ZoomIn zooms towards the center of the viewport.
+func (v *viewport) ZoomIn() {
+	v.zoomAt(f32.Point{}, f32.Point{}, v.scale()*zoomStep)
+}
+
+// ZoomOut zooms away from the center of the viewport.
+func (v *viewport) ZoomOut() {
+	v.zoomAt(f32.Point{}, f32.Point{}, v.scale()/zoomStep)
+}
+
+// Reset shows the whole content again.
+func (v *viewport) Reset() {
+	v.zoom = minZoom
+	v.pan = f32.Point{}
+}
+
+// zoomAt changes the zoom while keeping the content under the given position in place.
+// The position is relative to the center of the viewport.
+func (v *viewport) zoomAt(pos, center f32.Point, zoom float32) {
+	if zoom < minZoom {
+		zoom = minZoom
+	} else if zoom > maxZoom {
+		zoom = maxZoom
+	}
+	if zoom == minZoom {
+		v.Reset()
+		return
+	}
+
+	q := pos.Sub(center)
+	v.pan = q.Sub(q.Sub(v.pan).Mul(zoom / v.scale()))
+	v.zoom = zoom
+}
+
+func (v *viewport) scale() float32 {
+	if v.zoom < minZoom {
+		return minZoom
+	}
+	return v.zoom
+}
+
+func (v *viewport) transform(center f32.Point) f32.Affine2D {
+	s := v.scale()
+	return f32.Affine2D{}.Scale(center, f32.Pt(s, s)).Offset(v.pan)
+}